@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var errValueTooLarge = errors.New("value is larger than allowed to cache")
+
+// Bounded is redis keeping large values, like gifs, within the budget of
+// their total size, so redis doesn't run out of memory as new values are
+// seen. The least recently used values are evicted first. Access times
+// are kept in a sorted set and sizes in a hash next to the values, so the
+// budget is shared by all instances. Expiration times of the values with
+// ttl are kept in another sorted set, so the expired values are taken out
+// of the budget. Only Get and Set are bounded, the other operations go
+// to redis as is.
+type Bounded struct {
+	*Redis
+	// keys of the access times, expiration times, sizes and their total
+	lruKey     string
+	expiresKey string
+	sizesKey   string
+	bytesKey   string
+	maxBytes   int64
+	// larger values are not cached at all
+	maxValueSize int64
+	now          func() time.Time
+}
+
+// BoundedUsage is how much of the budget is taken.
+type BoundedUsage struct {
+	Values   int64
+	Bytes    int64
+	MaxBytes int64
+}
+
+// forgetExpiredScript takes the values expired by the given time out of
+// the budget, it starts both the scripts working with the budget.
+// Expired values are deleted in case the clock of redis is behind.
+//
+// KEYS: access times, expiration times, sizes, total size
+// ARGV: current time in milliseconds
+const forgetExpiredScript = `
+local function forget(key)
+	local size = tonumber(redis.call("HGET", KEYS[3], key) or "0")
+	redis.call("HDEL", KEYS[3], key)
+	redis.call("ZREM", KEYS[1], key)
+	redis.call("ZREM", KEYS[2], key)
+	redis.call("DEL", key)
+	return redis.call("DECRBY", KEYS[4], size)
+end
+for _, key in ipairs(redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1])) do
+	forget(key)
+end
+`
+
+// setBoundedScript puts the value and evicts the least recently used ones
+// until the total size fits the budget, atomically, so the instances
+// don't race for the budget. The value itself is never evicted, even if
+// another one was used in the same millisecond. Evicted keys are not
+// declared in KEYS, so the script doesn't work with redis cluster.
+//
+// KEYS: access times, expiration times, sizes, total size, value
+// ARGV: current time in milliseconds, value, max bytes, ttl in milliseconds or 0
+var setBoundedScript = redis.NewScript(forgetExpiredScript + `
+local size = string.len(ARGV[2])
+local ttl = tonumber(ARGV[4])
+if ttl > 0 then
+	redis.call("SET", KEYS[5], ARGV[2], "PX", ttl)
+	redis.call("ZADD", KEYS[2], tonumber(ARGV[1]) + ttl, KEYS[5])
+else
+	redis.call("SET", KEYS[5], ARGV[2])
+	redis.call("ZREM", KEYS[2], KEYS[5])
+end
+local old = tonumber(redis.call("HGET", KEYS[3], KEYS[5]) or "0")
+redis.call("HSET", KEYS[3], KEYS[5], size)
+redis.call("ZADD", KEYS[1], ARGV[1], KEYS[5])
+local total = redis.call("INCRBY", KEYS[4], size - old)
+while total > tonumber(ARGV[3]) do
+	local oldest = nil
+	for _, key in ipairs(redis.call("ZRANGE", KEYS[1], 0, 1)) do
+		if key ~= KEYS[5] then
+			oldest = key
+			break
+		end
+	end
+	if not oldest then
+		-- the counter drifted from the tracked values, only the value is left
+		redis.call("SET", KEYS[4], size)
+		break
+	end
+	total = forget(oldest)
+end
+return total
+`)
+
+// usageScript returns the number of values and their total size.
+//
+// KEYS: access times, expiration times, sizes, total size
+// ARGV: current time in milliseconds
+var usageScript = redis.NewScript(forgetExpiredScript + `
+return {redis.call("ZCARD", KEYS[1]), tonumber(redis.call("GET", KEYS[4]) or "0")}
+`)
+
+// NewBounded creates the bounded cache in redis, its bookkeeping
+// keys start with name. maxValueSize should not exceed maxBytes.
+func NewBounded(r *Redis, name string, maxBytes, maxValueSize int64) *Bounded {
+	return &Bounded{
+		Redis:        r,
+		lruKey:       name + ":lru",
+		expiresKey:   name + ":expires",
+		sizesKey:     name + ":sizes",
+		bytesKey:     name + ":bytes",
+		maxBytes:     maxBytes,
+		maxValueSize: maxValueSize,
+		now:          time.Now,
+	}
+}
+
+// Get returns the value and marks it as recently used.
+func (b *Bounded) Get(key string) ([]byte, error) {
+	if err := b.available(); err != nil {
+		return nil, err
+	}
+	pipe := b.client.Pipeline()
+	get := pipe.Get(key)
+	// only the values put by Set are tracked
+	pipe.ZAddXX(b.lruKey, redis.Z{Score: float64(b.nowMillis()), Member: key})
+	pipe.Exec()
+	value, err := get.Bytes()
+	if err != nil {
+		return nil, b.wrap(err)
+	}
+	return value, nil
+}
+
+// Set puts the value evicting the least recently used ones if the
+// budget is exceeded. Values larger than maxValueSize are not put.
+func (b *Bounded) Set(key string, value []byte, ttl time.Duration) error {
+	if int64(len(value)) > b.maxValueSize {
+		return fmt.Errorf("%w: %d bytes", errValueTooLarge, len(value))
+	}
+	if err := b.available(); err != nil {
+		return err
+	}
+	return b.wrap(setBoundedScript.Run(
+		b.client,
+		append(b.budgetKeys(), key),
+		b.nowMillis(),
+		value,
+		b.maxBytes,
+		ttl.Milliseconds(),
+	).Err())
+}
+
+func (b *Bounded) budgetKeys() []string {
+	return []string{b.lruKey, b.expiresKey, b.sizesKey, b.bytesKey}
+}
+
+func (b *Bounded) nowMillis() int64 {
+	return b.now().UnixNano() / int64(time.Millisecond)
+}
+
+// Usage returns how much of the budget is taken.
+func (b *Bounded) Usage() (BoundedUsage, error) {
+	if err := b.available(); err != nil {
+		return BoundedUsage{}, err
+	}
+	result, err := usageScript.Run(b.client, b.budgetKeys(), b.nowMillis()).Result()
+	if err != nil {
+		return BoundedUsage{}, b.wrap(err)
+	}
+	counts, ok := result.([]interface{})
+	if !ok || len(counts) != 2 {
+		return BoundedUsage{}, fmt.Errorf("unexpected usage of the budget: %v", result)
+	}
+	values, _ := counts[0].(int64)
+	bytes, _ := counts[1].(int64)
+	return BoundedUsage{values, bytes, b.maxBytes}, nil
+}
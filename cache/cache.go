@@ -0,0 +1,107 @@
+// Package cache holds the storages the upstream responses are cached in
+// and the read-through policy shared by all the stores of the service.
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+)
+
+// ErrMiss is returned when there is no value by the key.
+var ErrMiss = errors.New("no data in cache by the given key")
+
+// ErrUnavailable is returned when the cache can't be reached,
+// the callers should go to the upstream without caching.
+var ErrUnavailable = errors.New("cache is not available")
+
+var errWrongType = errors.New("value of another type is stored by the key")
+
+// Cache is a storage of strings, hashes and sets with expiration.
+// Zero ttl means the value never expires.
+type Cache interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	// HGetAll returns all the fields of the hash, ErrMiss if there are none.
+	HGetAll(key string) (map[string]string, error)
+	// HSet replaces the hash by the key with the given fields.
+	HSet(key string, fields map[string]string, ttl time.Duration) error
+	// SRandMember returns a random member of the set.
+	SRandMember(key string) (string, error)
+	// SAdd adds the members to the set and resets its ttl.
+	SAdd(key string, ttl time.Duration, members ...string) error
+}
+
+// TierStats are the counters of one cache tier. Values and Bytes
+// are known only for the in-memory cache.
+type TierStats struct {
+	Name   string
+	Hits   int64
+	Misses int64
+	Values int
+	Bytes  int64
+}
+
+// Stats returns the counters of the tiers of the caches summed by the tier
+// name, the in-memory cache shared by several caches is counted once.
+// The caches not counting hits and misses are skipped.
+func Stats(caches ...Cache) []TierStats {
+	var result []TierStats
+	add := func(stats TierStats) {
+		for i := range result {
+			if result[i].Name == stats.Name {
+				result[i].Hits += stats.Hits
+				result[i].Misses += stats.Misses
+				result[i].Values += stats.Values
+				result[i].Bytes += stats.Bytes
+				return
+			}
+		}
+		result = append(result, stats)
+	}
+	counted := make(map[*Memory]bool)
+	addMemory := func(m *Memory) {
+		if !counted[m] {
+			counted[m] = true
+			add(m.Stats())
+		}
+	}
+	for _, c := range caches {
+		switch c := c.(type) {
+		case *Memory:
+			addMemory(c)
+		case *Tiered:
+			addMemory(c.l1)
+			add(c.l2Stats())
+		}
+	}
+	return result
+}
+
+// ReadThrough gets the value from cache with get. On a miss the value is
+// fetched from upstream with fetch and put into cache with set, if cache
+// is not available the value is fetched without caching. Values are passed
+// through the variables captured by the functions. name is used in logs.
+func ReadThrough(logger *common.Logger, name string, get, fetch, set func() error) error {
+	err := get()
+	switch {
+	case err == nil:
+		logger.LogIfVerbose(name + ": returning data from cache")
+		return nil
+	case errors.Is(err, ErrUnavailable):
+		logger.LogIfVerbose(name + ": cache not available, falling back to api")
+		return fetch()
+	case !errors.Is(err, ErrMiss):
+		return err
+	}
+	if err := fetch(); err != nil {
+		return err
+	}
+	if err := set(); err != nil {
+		logger.LogIfVerbose(name + ": data was not cached: " + err.Error())
+		return nil
+	}
+	logger.LogIfVerbose(name + ": no data in cache, adding")
+	return nil
+}
@@ -0,0 +1,408 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+// testCache checks the behaviour all the caches storing values share.
+func testCache(t *testing.T, c Cache) {
+	if _, err := c.Get("missing"); err != ErrMiss {
+		t.Fatalf("Get: expected %v, got %v", ErrMiss, err)
+	}
+	if err := c.Set("string", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.Get("string"); err != nil || string(value) != "value" {
+		t.Fatalf("Get: expected value, got %q, %v", value, err)
+	}
+
+	if _, err := c.HGetAll("missing"); err != ErrMiss {
+		t.Fatalf("HGetAll: expected %v, got %v", ErrMiss, err)
+	}
+	if err := c.HSet("hash", map[string]string{"a": "1", "b": "2"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// fields are replaced, not merged
+	fields := map[string]string{"a": "3"}
+	if err := c.HSet("hash", fields, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.HGetAll("hash"); err != nil || !reflect.DeepEqual(value, fields) {
+		t.Fatalf("HGetAll: expected %v, got %v, %v", fields, value, err)
+	}
+
+	if _, err := c.SRandMember("missing"); err != ErrMiss {
+		t.Fatalf("SRandMember: expected %v, got %v", ErrMiss, err)
+	}
+	if err := c.SAdd("set", time.Minute, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SAdd("set", time.Minute, "b"); err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		member, err := c.SRandMember("set")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[member] = true
+	}
+	if !reflect.DeepEqual(seen, map[string]bool{"a": true, "b": true}) {
+		t.Fatalf("SRandMember: expected members a and b, got %v", seen)
+	}
+}
+
+func TestRedis(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	c := NewRedis(client)
+	testCache(t, c)
+	if ttl := mr.TTL("hash"); ttl != time.Minute {
+		t.Fatalf("expected ttl of a minute, got %v", ttl)
+	}
+	if err := c.Set("forever", []byte("value"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("forever"); ttl != 0 {
+		t.Fatalf("expected no ttl, got %v", ttl)
+	}
+
+	mr.Close()
+	// the pooled connection fails first, then the new one can't be dialed
+	c.Get("string")
+	if _, err := c.Get("string"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected %v when redis is down, got %v", ErrUnavailable, err)
+	}
+	// redis is not asked until the cooldown passes
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("string"); err != ErrUnavailable {
+		t.Fatalf("expected %v during cooldown, got %v", ErrUnavailable, err)
+	}
+}
+
+func TestBounded(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	c := NewBounded(NewRedis(client), "blobs", 10, 5)
+	now := time.Now()
+	c.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+	testCache(t, c)
+	mr.FlushAll()
+
+	for _, key := range [...]string{"a", "b", "c"} {
+		if err := c.Set(key, []byte("1234"), 0); err != nil {
+			t.Fatal(err)
+		}
+		// a becomes the most recently used, so b is evicted
+		c.Get("a")
+	}
+	if _, err := c.Get("b"); err != ErrMiss {
+		t.Fatalf("expected least recently used value to be evicted, got %v", err)
+	}
+	for _, key := range [...]string{"a", "c"} {
+		if _, err := c.Get(key); err != nil {
+			t.Fatalf("expected value %s to be kept, got %v", key, err)
+		}
+	}
+	// replaced value is counted once
+	if err := c.Set("c", []byte("12345"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := c.Usage(); err != nil || usage != (BoundedUsage{2, 9, 10}) {
+		t.Fatalf("expected 2 values of 9 bytes, got %+v, %v", usage, err)
+	}
+	if ttl := mr.TTL("c"); ttl != time.Minute {
+		t.Fatalf("expected ttl of a minute, got %v", ttl)
+	}
+	// expired value is taken out of the budget
+	now = now.Add(time.Minute)
+	mr.FastForward(time.Minute)
+	if usage, err := c.Usage(); err != nil || usage != (BoundedUsage{1, 4, 10}) {
+		t.Fatalf("expected 1 value of 4 bytes after expiration, got %+v, %v", usage, err)
+	}
+	// the counter drifted from the tracked values
+	mr.Set("blobs:bytes", "100")
+	if err := c.Set("d", []byte("12345"), 0); err != nil {
+		t.Fatalf("expected drifted counter to be reset, got %v", err)
+	}
+	if usage, err := c.Usage(); err != nil || usage != (BoundedUsage{1, 5, 10}) {
+		t.Fatalf("expected 1 value of 5 bytes after reset, got %+v, %v", usage, err)
+	}
+
+	if err := c.Set("large", []byte("123456"), 0); !errors.Is(err, errValueTooLarge) {
+		t.Fatalf("expected %v, got %v", errValueTooLarge, err)
+	}
+	if mr.Exists("large") {
+		t.Fatal("expected too large value not to be cached")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testCache(t, NewMemory(10, 0))
+}
+
+func TestMemoryEviction(t *testing.T) {
+	c := NewMemory(2, 0)
+	c.Set("a", []byte("a"), 0)
+	c.Set("b", []byte("b"), 0)
+	// a becomes the most recently used, so b is evicted
+	c.Get("a")
+	c.Set("c", []byte("c"), 0)
+	if _, err := c.Get("b"); err != ErrMiss {
+		t.Fatalf("expected least recently used value to be evicted, got %v", err)
+	}
+	if _, err := c.Get("a"); err != nil {
+		t.Fatalf("expected recently used value to be kept, got %v", err)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 values, got %d", c.Len())
+	}
+}
+
+func TestMemoryMaxBytes(t *testing.T) {
+	// key and value of 5 bytes each, only one fits
+	c := NewMemory(0, 9)
+	c.Set("a", []byte("aaaa"), 0)
+	c.Set("b", []byte("bbbb"), 0)
+	if _, err := c.Get("a"); err != ErrMiss {
+		t.Fatalf("expected value to be evicted over the size limit, got %v", err)
+	}
+	c.Set("c", []byte("too large to fit"), 0)
+	if _, err := c.Get("b"); err != nil {
+		t.Fatalf("expected value not to be evicted for the one that doesn't fit, got %v", err)
+	}
+	if stats := c.Stats(); stats.Values != 1 || stats.Bytes != 5 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestMemoryExpiration(t *testing.T) {
+	c := NewMemory(10, 0)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Set("a", []byte("a"), time.Minute)
+	c.Set("forever", []byte("forever"), 0)
+	now = now.Add(time.Minute)
+	if _, err := c.Get("a"); err != ErrMiss {
+		t.Fatalf("expected expired value to be missing, got %v", err)
+	}
+	if _, err := c.Get("forever"); err != nil {
+		t.Fatalf("expected value without ttl to be kept, got %v", err)
+	}
+	if err := c.SAdd("forever", 0, "a"); err != errWrongType {
+		t.Fatalf("expected %v, got %v", errWrongType, err)
+	}
+}
+
+func TestTiered(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	l1 := NewMemory(0, 1<<20)
+	c := NewTiered(l1, NewRedis(client), time.Minute)
+	testCache(t, c)
+	// ttl in redis is not bounded by the ttl in memory
+	if err := c.Set("forever", []byte("value"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("forever"); ttl != 0 {
+		t.Fatalf("expected no ttl in redis, got %v", ttl)
+	}
+
+	// values from redis are kept in memory
+	before := c.Stats()
+	mr.HSet("rates", "USD", "1")
+	for i := 0; i < 2; i++ {
+		if fields, err := c.HGetAll("rates"); err != nil || fields["USD"] != "1" {
+			t.Fatalf("expected rates, got %v, %v", fields, err)
+		}
+	}
+	after := c.Stats()
+	if after[0].Hits-before[0].Hits != 1 || after[1].Hits-before[1].Hits != 1 {
+		t.Fatalf("expected rates to be taken from redis once, got %+v, %+v", before, after)
+	}
+
+	now := time.Now()
+	l1.now = func() time.Time { return now }
+	c.Set("gif", []byte("gif"), time.Hour)
+	mr.Close()
+	// the pooled connection fails first, then the new one can't be dialed
+	c.SRandMember("set")
+	if value, err := c.Get("gif"); err != nil || string(value) != "gif" {
+		t.Fatalf("expected value to be served from memory while redis is down, got %q, %v", value, err)
+	}
+	if member, err := c.SRandMember("set"); err != nil || (member != "a" && member != "b") {
+		t.Fatalf("expected set member from memory while redis is down, got %q, %v", member, err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := c.Get("gif"); err != ErrMiss {
+		t.Fatalf("expected miss after ttl in memory, got %v", err)
+	}
+	// fetched value is kept in memory even if it can't be put into redis
+	if err := c.Set("gif", []byte("gif"), time.Hour); err != nil {
+		t.Fatalf("expected unavailable redis to be ignored, got %v", err)
+	}
+	if _, err := c.Get("gif"); err != nil {
+		t.Fatalf("expected value to be served from memory, got %v", err)
+	}
+}
+
+// readThroughConcurrently calls ReadThrough of the flights with the same key
+// at once, the fetch blocks until all the calls are made. Returns the
+// number of fetches.
+func readThroughConcurrently(t *testing.T, c Cache, flights ...*Flight) int64 {
+	var fetches int64
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, f := range flights {
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(f *Flight) {
+				defer wg.Done()
+				value, err := f.ReadThrough(
+					context.Background(),
+					&common.Logger{},
+					"test",
+					"key",
+					func() (interface{}, error) { return c.Get("key") },
+					func() (interface{}, error) {
+						atomic.AddInt64(&fetches, 1)
+						<-release
+						return []byte("value"), nil
+					},
+					func(value interface{}) error { return c.Set("key", value.([]byte), time.Minute) },
+				)
+				if err != nil || string(value.([]byte)) != "value" {
+					t.Errorf("expected value, got %v, %v", value, err)
+				}
+			}(f)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return fetches
+}
+
+func TestFlight(t *testing.T) {
+	if fetches := readThroughConcurrently(t, Noop{}, NewFlight(nil)); fetches != 1 {
+		t.Fatalf("expected concurrent fetches to be coalesced, got %d fetches", fetches)
+	}
+
+	// the caller whose fetch is shared is cancelled
+	f := NewFlight(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	go f.ReadThrough(
+		ctx,
+		&common.Logger{},
+		"test",
+		"key",
+		func() (interface{}, error) { return nil, ErrMiss },
+		func() (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		func(interface{}) error { return nil },
+	)
+	<-started
+	time.AfterFunc(50*time.Millisecond, cancel)
+	value, err := f.ReadThrough(
+		context.Background(),
+		&common.Logger{},
+		"test",
+		"key",
+		func() (interface{}, error) { return nil, ErrMiss },
+		func() (interface{}, error) { return "value", nil },
+		func(interface{}) error { return nil },
+	)
+	if err != nil || value != "value" {
+		t.Fatalf("expected value to be fetched anew, got %v, %v", value, err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	c := NewRedis(client)
+	// the flights of two instances sharing redis
+	lock := NewLock(c, time.Minute)
+	if fetches := readThroughConcurrently(t, c, NewFlight(lock), NewFlight(lock)); fetches != 1 {
+		t.Fatalf("expected fetches to be coalesced across instances, got %d fetches", fetches)
+	}
+	if mr.Exists(lockKey("key")) {
+		t.Fatal("expected lock to be released")
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	errUpstream := errors.New("upstream failed")
+	testCases := [...]struct {
+		name        string
+		getErr      error
+		fetchErr    error
+		expectedErr error
+		fetched     bool
+		set         bool
+	}{
+		{"hit", nil, nil, nil, false, false},
+		{"miss", ErrMiss, nil, nil, true, true},
+		{"miss and upstream fails", ErrMiss, errUpstream, errUpstream, true, false},
+		{"unavailable", ErrUnavailable, nil, nil, true, false},
+		{"cache fails", errWrongType, nil, errWrongType, false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fetched, set bool
+			err := ReadThrough(
+				&common.Logger{},
+				tc.name,
+				func() error { return tc.getErr },
+				func() error { fetched = true; return tc.fetchErr },
+				func() error { set = true; return nil },
+			)
+			if err != tc.expectedErr || fetched != tc.fetched || set != tc.set {
+				t.Fatalf(
+					"expected error %v, fetched %t, set %t, got %v, %t, %t",
+					tc.expectedErr, tc.fetched, tc.set, err, fetched, set,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Ghytro/ab_interview/common"
+	"golang.org/x/sync/singleflight"
+)
+
+// Flight coalesces concurrent upstream fetches of the same key, so on
+// a cold cache only one of the concurrent requests goes upstream. Within
+// the instance the callers wait for the result of the first one. With the
+// lock the instances sharing redis wait for the one holding the lock to
+// put the value into cache and take it from there.
+type Flight struct {
+	group singleflight.Group
+	// nil to coalesce within the instance only
+	lock *Lock
+}
+
+func NewFlight(lock *Lock) *Flight {
+	return &Flight{lock: lock}
+}
+
+// ReadThrough works like the ReadThrough function, but the concurrent
+// callers with the same key share one fetch and set, so the values are
+// passed as results and the shared ones should not be modified. Each
+// caller waits until its own ctx is done, if the caller whose fetch is
+// shared is cancelled, the others fetch anew.
+func (f *Flight) ReadThrough(
+	ctx context.Context,
+	logger *common.Logger,
+	name, key string,
+	get, fetch func() (interface{}, error),
+	set func(value interface{}) error,
+) (interface{}, error) {
+	value, err := get()
+	switch {
+	case err == nil:
+		logger.LogIfVerbose(name + ": returning data from cache")
+		return value, nil
+	case errors.Is(err, ErrUnavailable):
+		logger.LogIfVerbose(name + ": cache not available, falling back to api")
+		return f.do(ctx, key, fetch)
+	case !errors.Is(err, ErrMiss):
+		return nil, err
+	}
+	return f.do(ctx, key, func() (interface{}, error) {
+		fetchAndSet := func() (interface{}, error) {
+			value, err := fetch()
+			if err != nil {
+				return nil, err
+			}
+			if err := set(value); err != nil {
+				logger.LogIfVerbose(name + ": data was not cached: " + err.Error())
+				return value, nil
+			}
+			logger.LogIfVerbose(name + ": no data in cache, adding")
+			return value, nil
+		}
+		if f.lock == nil {
+			return fetchAndSet()
+		}
+		return f.lock.Fetch(ctx, key, get, fetchAndSet)
+	})
+}
+
+// do returns the result of fn shared by the concurrent callers with the key.
+func (f *Flight) do(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error) {
+	for {
+		results := f.group.DoChan(key, fn)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-results:
+			if result.Shared && isContextErr(result.Err) && ctx.Err() == nil {
+				continue
+			}
+			return result.Val, result.Err
+		}
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// how often the instances waiting for the lock check the cache
+const lockPollInterval = 50 * time.Millisecond
+
+// Lock serializes the fetches of the same key across the instances
+// sharing redis. The lock expires after ttl, so the instance which
+// failed to release it doesn't block the others for long.
+type Lock struct {
+	redis *Redis
+	ttl   time.Duration
+}
+
+// unlockScript deletes the lock only if it is still held by the
+// caller and was not taken by another instance after expiration.
+//
+// KEYS: lock
+// ARGV: token of the caller
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func NewLock(r *Redis, ttl time.Duration) *Lock {
+	return &Lock{r, ttl}
+}
+
+func lockKey(key string) string {
+	return "lock:" + key
+}
+
+func newLockToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// Fetch calls fetch holding the lock of the key, fetch is expected to put
+// the value into cache. While the lock is held by another instance, the
+// cache is checked with get until the value appears there or the lock is
+// released, then the lock is tried again. If redis is not available,
+// fetch is called without the lock.
+func (l *Lock) Fetch(ctx context.Context, key string, get, fetch func() (interface{}, error)) (interface{}, error) {
+	lock, token := lockKey(key), newLockToken()
+	for {
+		if err := l.redis.available(); err != nil {
+			return fetch()
+		}
+		locked, err := l.redis.client.SetNX(lock, token, l.ttl).Result()
+		if err != nil {
+			l.redis.wrap(err)
+			return fetch()
+		}
+		if locked {
+			defer unlockScript.Run(l.redis.client, []string{lock}, token)
+			return fetch()
+		}
+
+		timer := time.NewTimer(lockPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if value, err := get(); err == nil {
+			return value, nil
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Memory is the in-process cache keeping at most maxValues values of
+// at most maxBytes in total, the least recently used ones are evicted
+// first. Zero limit means no limit. It is not shared between instances
+// of the service and is lost on restart.
+type Memory struct {
+	maxValues int
+	maxBytes  int64
+	bytes     int64
+	entries   map[string]*list.Element
+	// front is the most recently used
+	order  *list.List
+	hits   int64
+	misses int64
+	m      sync.Mutex
+	now    func() time.Time
+}
+
+type memoryEntry struct {
+	key string
+	// []byte, map[string]string or set
+	value   interface{}
+	size    int64
+	expires time.Time
+}
+
+type set map[string]struct{}
+
+func NewMemory(maxValues int, maxBytes int64) *Memory {
+	return &Memory{
+		maxValues: maxValues,
+		maxBytes:  maxBytes,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+		now:       time.Now,
+	}
+}
+
+// Len returns the number of values in cache, including the expired
+// ones that were not evicted yet.
+func (c *Memory) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the counters of the cache.
+func (c *Memory) Stats() TierStats {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return TierStats{"memory", c.hits, c.misses, c.order.Len(), c.bytes}
+}
+
+// valueSize is the approximate memory taken by the value.
+func valueSize(key string, value interface{}) int64 {
+	size := int64(len(key))
+	switch v := value.(type) {
+	case []byte:
+		size += int64(len(v))
+	case map[string]string:
+		for k, field := range v {
+			size += int64(len(k) + len(field))
+		}
+	case set:
+		for member := range v {
+			size += int64(len(member))
+		}
+	}
+	return size
+}
+
+// lookup returns the entry by the key and marks it as recently used.
+func (c *Memory) lookup(key string) (*memoryEntry, error) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, ErrMiss
+	}
+	c.order.MoveToFront(elem)
+	return entry, nil
+}
+
+// get is lookup counting hits and misses.
+func (c *Memory) get(key string) (*memoryEntry, error) {
+	entry, err := c.lookup(key)
+	if err != nil {
+		c.misses++
+		return nil, err
+	}
+	c.hits++
+	return entry, nil
+}
+
+func (c *Memory) put(key string, value interface{}, ttl time.Duration) {
+	entry := &memoryEntry{key: key, value: value, size: valueSize(key, value)}
+	if ttl > 0 {
+		entry.expires = c.now().Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		// would evict everything and still not fit
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += entry.size
+	for (c.maxValues > 0 && c.order.Len() > c.maxValues) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Memory) remove(elem *list.Element) {
+	entry := elem.Value.(*memoryEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+func (c *Memory) Get(key string) ([]byte, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	entry, err := c.get(key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := entry.value.([]byte)
+	if !ok {
+		return nil, errWrongType
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (c *Memory) Set(key string, value []byte, ttl time.Duration) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.put(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (c *Memory) HGetAll(key string) (map[string]string, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	entry, err := c.get(key)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := entry.value.(map[string]string)
+	if !ok {
+		return nil, errWrongType
+	}
+	result := make(map[string]string, len(fields))
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (c *Memory) HSet(key string, fields map[string]string, ttl time.Duration) error {
+	copied := make(map[string]string, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.put(key, copied, ttl)
+	return nil
+}
+
+func (c *Memory) SRandMember(key string) (string, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	entry, err := c.get(key)
+	if err != nil {
+		return "", err
+	}
+	members, ok := entry.value.(set)
+	if !ok {
+		return "", errWrongType
+	}
+	i := rand.Intn(len(members))
+	for member := range members {
+		if i == 0 {
+			return member, nil
+		}
+		i--
+	}
+	return "", ErrMiss
+}
+
+func (c *Memory) SAdd(key string, ttl time.Duration, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	merged := make(set)
+	if entry, err := c.lookup(key); err == nil {
+		existing, ok := entry.value.(set)
+		if !ok {
+			return errWrongType
+		}
+		for member := range existing {
+			merged[member] = struct{}{}
+		}
+	}
+	for _, member := range members {
+		merged[member] = struct{}{}
+	}
+	c.put(key, merged, ttl)
+	return nil
+}
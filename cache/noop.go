@@ -0,0 +1,30 @@
+package cache
+
+import "time"
+
+// Noop caches nothing, every value is fetched from upstream.
+type Noop struct{}
+
+func (Noop) Get(string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+func (Noop) Set(string, []byte, time.Duration) error {
+	return nil
+}
+
+func (Noop) HGetAll(string) (map[string]string, error) {
+	return nil, ErrMiss
+}
+
+func (Noop) HSet(string, map[string]string, time.Duration) error {
+	return nil
+}
+
+func (Noop) SRandMember(string) (string, error) {
+	return "", ErrMiss
+}
+
+func (Noop) SAdd(string, time.Duration, ...string) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/go-redis/redis"
+)
+
+// Redis is the cache shared by all instances of the service. When redis
+// doesn't respond, it is considered unavailable for a while and the
+// requests to it fail with ErrUnavailable without going to network.
+type Redis struct {
+	client *redis.Client
+	health *common.HealthChecker
+}
+
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client, common.NewHealthChecker(common.RedisHealthCheckCooldown)}
+}
+
+// Client returns the underlying redis client.
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+func (r *Redis) available() error {
+	if !r.health.IsAvailable() {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// wrap converts redis errors to the cache ones.
+func (r *Redis) wrap(err error) error {
+	switch {
+	case err == redis.Nil:
+		return ErrMiss
+	case common.IsBadRedisConnectionErr(err):
+		r.health.SetUnavailable()
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
+
+func (r *Redis) Get(key string) ([]byte, error) {
+	if err := r.available(); err != nil {
+		return nil, err
+	}
+	value, err := r.client.Get(key).Bytes()
+	if err != nil {
+		return nil, r.wrap(err)
+	}
+	return value, nil
+}
+
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	if err := r.available(); err != nil {
+		return err
+	}
+	return r.wrap(r.client.Set(key, value, ttl).Err())
+}
+
+func (r *Redis) HGetAll(key string) (map[string]string, error) {
+	if err := r.available(); err != nil {
+		return nil, err
+	}
+	fields, err := r.client.HGetAll(key).Result()
+	if err != nil {
+		return nil, r.wrap(err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrMiss
+	}
+	return fields, nil
+}
+
+func (r *Redis) HSet(key string, fields map[string]string, ttl time.Duration) error {
+	if err := r.available(); err != nil {
+		return err
+	}
+	redisFields := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		redisFields[k] = v
+	}
+	// in a transaction, so readers never see the hash half replaced
+	// or without ttl
+	pipe := r.client.TxPipeline()
+	pipe.Del(key)
+	pipe.HMSet(key, redisFields)
+	if ttl > 0 {
+		pipe.Expire(key, ttl)
+	}
+	_, err := pipe.Exec()
+	return r.wrap(err)
+}
+
+func (r *Redis) SRandMember(key string) (string, error) {
+	if err := r.available(); err != nil {
+		return "", err
+	}
+	member, err := r.client.SRandMember(key).Result()
+	if err != nil {
+		return "", r.wrap(err)
+	}
+	return member, nil
+}
+
+func (r *Redis) SAdd(key string, ttl time.Duration, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	if err := r.available(); err != nil {
+		return err
+	}
+	redisMembers := make([]interface{}, len(members))
+	for i, m := range members {
+		redisMembers[i] = m
+	}
+	pipe := r.client.TxPipeline()
+	pipe.SAdd(key, redisMembers...)
+	if ttl > 0 {
+		pipe.Expire(key, ttl)
+	}
+	_, err := pipe.Exec()
+	return r.wrap(err)
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Tiered is the bounded in-memory cache (L1) in front of the shared one
+// (L2). Strings and hashes are served from L1 when possible, so the hot
+// values don't go through network and survive L2 outages. Values taken
+// from L2 are kept in L1 for at most l1TTL, as their ttl in L2 is unknown,
+// so L1 may lag behind L2 for that long. Sets are always taken from L2,
+// as only a random member is read from it, L1 serves them only while L2
+// is unavailable.
+type Tiered struct {
+	l1    *Memory
+	l2    Cache
+	l1TTL time.Duration
+	// L2 counters, L1 counts by itself
+	l2Hits   int64
+	l2Misses int64
+}
+
+func NewTiered(l1 *Memory, l2 Cache, l1TTL time.Duration) *Tiered {
+	return &Tiered{l1: l1, l2: l2, l1TTL: l1TTL}
+}
+
+// L2 returns the cache behind the in-memory one.
+func (c *Tiered) L2() Cache {
+	return c.l2
+}
+
+// Stats returns the counters of L1 and L2.
+func (c *Tiered) Stats() []TierStats {
+	return []TierStats{c.l1.Stats(), c.l2Stats()}
+}
+
+func (c *Tiered) l2Stats() TierStats {
+	return TierStats{Name: "shared", Hits: atomic.LoadInt64(&c.l2Hits), Misses: atomic.LoadInt64(&c.l2Misses)}
+}
+
+// l1TTLFor bounds the ttl of the value in L1 with l1TTL.
+func (c *Tiered) l1TTLFor(ttl time.Duration) time.Duration {
+	if ttl == 0 || ttl > c.l1TTL {
+		return c.l1TTL
+	}
+	return ttl
+}
+
+// fromL2 counts the result of reading from L2. Unavailable L2 is
+// reported as a miss, so the value is fetched and put into L1.
+func (c *Tiered) fromL2(err error) error {
+	switch {
+	case err == nil:
+		atomic.AddInt64(&c.l2Hits, 1)
+		return nil
+	case errors.Is(err, ErrMiss), errors.Is(err, ErrUnavailable):
+		atomic.AddInt64(&c.l2Misses, 1)
+		return ErrMiss
+	}
+	return err
+}
+
+// toL2 reports the value as cached if it is in L1 even though L2 is
+// not available.
+func toL2(err error) error {
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	return err
+}
+
+func (c *Tiered) Get(key string) ([]byte, error) {
+	if value, err := c.l1.Get(key); err == nil {
+		return value, nil
+	}
+	value, err := c.l2.Get(key)
+	if err := c.fromL2(err); err != nil {
+		return nil, err
+	}
+	c.l1.Set(key, value, c.l1TTL)
+	return value, nil
+}
+
+func (c *Tiered) Set(key string, value []byte, ttl time.Duration) error {
+	c.l1.Set(key, value, c.l1TTLFor(ttl))
+	return toL2(c.l2.Set(key, value, ttl))
+}
+
+func (c *Tiered) HGetAll(key string) (map[string]string, error) {
+	if fields, err := c.l1.HGetAll(key); err == nil {
+		return fields, nil
+	}
+	fields, err := c.l2.HGetAll(key)
+	if err := c.fromL2(err); err != nil {
+		return nil, err
+	}
+	c.l1.HSet(key, fields, c.l1TTL)
+	return fields, nil
+}
+
+func (c *Tiered) HSet(key string, fields map[string]string, ttl time.Duration) error {
+	c.l1.HSet(key, fields, c.l1TTLFor(ttl))
+	return toL2(c.l2.HSet(key, fields, ttl))
+}
+
+func (c *Tiered) SRandMember(key string) (string, error) {
+	member, err := c.l2.SRandMember(key)
+	if errors.Is(err, ErrUnavailable) {
+		return c.l1.SRandMember(key)
+	}
+	return member, c.fromL2(err)
+}
+
+func (c *Tiered) SAdd(key string, ttl time.Duration, members ...string) error {
+	c.l1.SAdd(key, c.l1TTLFor(ttl), members...)
+	return toL2(c.l2.SAdd(key, ttl, members...))
+}
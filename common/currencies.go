@@ -2,192 +2,232 @@ package common
 
 import (
 	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 var errIncorrectCurrency = errors.New("incorrect currency code")
 
-var currencies = map[string]string{
-	"AED": "United Arab Emirates Dirham",
-	"AFN": "Afghan Afghani",
-	"ALL": "Albanian Lek",
-	"AMD": "Armenian Dram",
-	"ANG": "Netherlands Antillean Guilder",
-	"AOA": "Angolan Kwanza",
-	"ARS": "Argentine Peso",
-	"AUD": "Australian Dollar",
-	"AWG": "Aruban Florin",
-	"AZN": "Azerbaijani Manat",
-	"BAM": "Bosnia-Herzegovina Convertible Mark",
-	"BBD": "Barbadian Dollar",
-	"BDT": "Bangladeshi Taka",
-	"BGN": "Bulgarian Lev",
-	"BHD": "Bahraini Dinar",
-	"BIF": "Burundian Franc",
-	"BMD": "Bermudan Dollar",
-	"BND": "Brunei Dollar",
-	"BOB": "Bolivian Boliviano",
-	"BRL": "Brazilian Real",
-	"BSD": "Bahamian Dollar",
-	"BTC": "Bitcoin",
-	"BTN": "Bhutanese Ngultrum",
-	"BWP": "Botswanan Pula",
-	"BYN": "Belarusian Ruble",
-	"BZD": "Belize Dollar",
-	"CAD": "Canadian Dollar",
-	"CDF": "Congolese Franc",
-	"CHF": "Swiss Franc",
-	"CLF": "Chilean Unit of Account (UF)",
-	"CLP": "Chilean Peso",
-	"CNH": "Chinese Yuan (Offshore)",
-	"CNY": "Chinese Yuan",
-	"COP": "Colombian Peso",
-	"CRC": "Costa Rican Colón",
-	"CUC": "Cuban Convertible Peso",
-	"CUP": "Cuban Peso",
-	"CVE": "Cape Verdean Escudo",
-	"CZK": "Czech Republic Koruna",
-	"DJF": "Djiboutian Franc",
-	"DKK": "Danish Krone",
-	"DOP": "Dominican Peso",
-	"DZD": "Algerian Dinar",
-	"EGP": "Egyptian Pound",
-	"ERN": "Eritrean Nakfa",
-	"ETB": "Ethiopian Birr",
-	"EUR": "Euro",
-	"FJD": "Fijian Dollar",
-	"FKP": "Falkland Islands Pound",
-	"GBP": "British Pound Sterling",
-	"GEL": "Georgian Lari",
-	"GGP": "Guernsey Pound",
-	"GHS": "Ghanaian Cedi",
-	"GIP": "Gibraltar Pound",
-	"GMD": "Gambian Dalasi",
-	"GNF": "Guinean Franc",
-	"GTQ": "Guatemalan Quetzal",
-	"GYD": "Guyanaese Dollar",
-	"HKD": "Hong Kong Dollar",
-	"HNL": "Honduran Lempira",
-	"HRK": "Croatian Kuna",
-	"HTG": "Haitian Gourde",
-	"HUF": "Hungarian Forint",
-	"IDR": "Indonesian Rupiah",
-	"ILS": "Israeli New Sheqel",
-	"IMP": "Manx pound",
-	"INR": "Indian Rupee",
-	"IQD": "Iraqi Dinar",
-	"IRR": "Iranian Rial",
-	"ISK": "Icelandic Króna",
-	"JEP": "Jersey Pound",
-	"JMD": "Jamaican Dollar",
-	"JOD": "Jordanian Dinar",
-	"JPY": "Japanese Yen",
-	"KES": "Kenyan Shilling",
-	"KGS": "Kyrgystani Som",
-	"KHR": "Cambodian Riel",
-	"KMF": "Comorian Franc",
-	"KPW": "North Korean Won",
-	"KRW": "South Korean Won",
-	"KWD": "Kuwaiti Dinar",
-	"KYD": "Cayman Islands Dollar",
-	"KZT": "Kazakhstani Tenge",
-	"LAK": "Laotian Kip",
-	"LBP": "Lebanese Pound",
-	"LKR": "Sri Lankan Rupee",
-	"LRD": "Liberian Dollar",
-	"LSL": "Lesotho Loti",
-	"LYD": "Libyan Dinar",
-	"MAD": "Moroccan Dirham",
-	"MDL": "Moldovan Leu",
-	"MGA": "Malagasy Ariary",
-	"MKD": "Macedonian Denar",
-	"MMK": "Myanma Kyat",
-	"MNT": "Mongolian Tugrik",
-	"MOP": "Macanese Pataca",
-	"MRU": "Mauritanian Ouguiya",
-	"MUR": "Mauritian Rupee",
-	"MVR": "Maldivian Rufiyaa",
-	"MWK": "Malawian Kwacha",
-	"MXN": "Mexican Peso",
-	"MYR": "Malaysian Ringgit",
-	"MZN": "Mozambican Metical",
-	"NAD": "Namibian Dollar",
-	"NGN": "Nigerian Naira",
-	"NIO": "Nicaraguan Córdoba",
-	"NOK": "Norwegian Krone",
-	"NPR": "Nepalese Rupee",
-	"NZD": "New Zealand Dollar",
-	"OMR": "Omani Rial",
-	"PAB": "Panamanian Balboa",
-	"PEN": "Peruvian Nuevo Sol",
-	"PGK": "Papua New Guinean Kina",
-	"PHP": "Philippine Peso",
-	"PKR": "Pakistani Rupee",
-	"PLN": "Polish Zloty",
-	"PYG": "Paraguayan Guarani",
-	"QAR": "Qatari Rial",
-	"RON": "Romanian Leu",
-	"RSD": "Serbian Dinar",
-	"RUB": "Russian Ruble",
-	"RWF": "Rwandan Franc",
-	"SAR": "Saudi Riyal",
-	"SBD": "Solomon Islands Dollar",
-	"SCR": "Seychellois Rupee",
-	"SDG": "Sudanese Pound",
-	"SEK": "Swedish Krona",
-	"SGD": "Singapore Dollar",
-	"SHP": "Saint Helena Pound",
-	"SLL": "Sierra Leonean Leone",
-	"SOS": "Somali Shilling",
-	"SRD": "Surinamese Dollar",
-	"SSP": "South Sudanese Pound",
-	"STD": "São Tomé and Príncipe Dobra (pre-2018)",
-	"STN": "São Tomé and Príncipe Dobra",
-	"SVC": "Salvadoran Colón",
-	"SYP": "Syrian Pound",
-	"SZL": "Swazi Lilangeni",
-	"THB": "Thai Baht",
-	"TJS": "Tajikistani Somoni",
-	"TMT": "Turkmenistani Manat",
-	"TND": "Tunisian Dinar",
-	"TOP": "Tongan Pa'anga",
-	"TRY": "Turkish Lira",
-	"TTD": "Trinidad and Tobago Dollar",
-	"TWD": "New Taiwan Dollar",
-	"TZS": "Tanzanian Shilling",
-	"UAH": "Ukrainian Hryvnia",
-	"UGX": "Ugandan Shilling",
-	"USD": "United States Dollar",
-	"UYU": "Uruguayan Peso",
-	"UZS": "Uzbekistan Som",
-	"VEF": "Venezuelan Bolívar Fuerte (Old)",
-	"VES": "Venezuelan Bolívar Soberano",
-	"VND": "Vietnamese Dong",
-	"VUV": "Vanuatu Vatu",
-	"WST": "Samoan Tala",
-	"XAF": "CFA Franc BEAC",
-	"XAG": "Silver Ounce",
-	"XAU": "Gold Ounce",
-	"XCD": "East Caribbean Dollar",
-	"XDR": "Special Drawing Rights",
-	"XOF": "CFA Franc BCEAO",
-	"XPD": "Palladium Ounce",
-	"XPF": "CFP Franc",
-	"XPT": "Platinum Ounce",
-	"YER": "Yemeni Rial",
-	"ZAR": "South African Rand",
-	"ZMW": "Zambian Kwacha",
-	"ZWL": "Zimbabwean Dollar",
+// CurrencyKind tells what stands behind the currency.
+type CurrencyKind string
+
+const (
+	CurrencyFiat   CurrencyKind = "fiat"
+	CurrencyCrypto CurrencyKind = "crypto"
+	CurrencyMetal  CurrencyKind = "metal"
+	// supranational units like special drawing rights
+	CurrencyUnitOfAccount CurrencyKind = "unit_of_account"
+)
+
+// NoMinorUnits are the minor units of the currencies that have no
+// minor unit in ISO 4217 (precious metals, special drawing rights)
+// or whose minor unit is not known.
+const NoMinorUnits = -1
+
+// significant digits of the amounts in currencies without minor units
+const noMinorUnitsPrecision = 6
+
+// Currency is the currency with its ISO 4217 metadata.
+type Currency struct {
+	Code string
+	// ISO 4217 numeric code, zero for codes outside of the standard
+	NumericCode int
+	Name        string
+	// number of digits after the decimal separator
+	MinorUnits int
+	Symbol     string
+	// ISO 3166 alpha-2 codes of the countries using the currency
+	Countries []string
+	// empty for the currencies known only by code and name
+	Kind CurrencyKind
+	// withdrawn from circulation, but still may have historical rates
+	Historical bool
 }
 
-func CurrencyExists(currencyCode string) bool {
-	_, ok := currencies[currencyCode]
+// FormatAmount formats the amount rounded to the minor units of
+// the currency, with its symbol or, if it has none, its code.
+func (c *Currency) FormatAmount(amount float64) string {
+	var number string
+	if c.MinorUnits == NoMinorUnits {
+		number = strconv.FormatFloat(math.Abs(amount), 'g', noMinorUnitsPrecision, 64)
+	} else {
+		number = strconv.FormatFloat(math.Abs(amount), 'f', c.MinorUnits, 64)
+	}
+	sign := ""
+	if amount < 0 && strings.Trim(number, "0.") != "" {
+		sign = "-"
+	}
+	switch {
+	case c.Symbol == "":
+		return sign + number + " " + c.Code
+	case utf8.RuneCountInString(c.Symbol) == 1:
+		return sign + c.Symbol + number
+	}
+	return sign + c.Symbol + " " + number
+}
+
+// CurrencyCatalog is the replaceable set of known currencies,
+// safe for concurrent use.
+type CurrencyCatalog struct {
+	currencies map[string]Currency
+	m          sync.RWMutex
+}
+
+// NewCurrencyCatalog creates the catalog of the builtin currencies.
+func NewCurrencyCatalog() *CurrencyCatalog {
+	return &CurrencyCatalog{currencies: builtinCurrenciesByCode}
+}
+
+var builtinCurrenciesByCode = make(map[string]Currency, len(builtinCurrencies))
+var builtinCurrenciesByNumericCode = make(map[int]Currency, len(builtinCurrencies))
+
+func init() {
+	for _, c := range builtinCurrencies {
+		builtinCurrenciesByCode[c.Code] = c
+		if c.NumericCode != 0 {
+			builtinCurrenciesByNumericCode[c.NumericCode] = c
+		}
+	}
+}
+
+var builtinCatalog = NewCurrencyCatalog()
+
+// Replace replaces the currencies in catalog with the given codes and
+// names. Metadata of the builtin currencies is kept, the others are known
+// only by code and name. Empty currencies are ignored, so the catalog
+// never gets empty.
+func (c *CurrencyCatalog) Replace(names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	currencies := make(map[string]Currency, len(names))
+	for code, name := range names {
+		currency, ok := builtinCurrenciesByCode[code]
+		if !ok {
+			currency = Currency{Code: code, Name: name, MinorUnits: NoMinorUnits}
+		}
+		currencies[code] = currency
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.currencies = currencies
+}
+
+// All returns all the currencies in catalog sorted by code.
+func (c *CurrencyCatalog) All() []Currency {
+	c.m.RLock()
+	result := make([]Currency, 0, len(c.currencies))
+	for _, currency := range c.currencies {
+		result = append(result, currency)
+	}
+	c.m.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+func (c *CurrencyCatalog) Lookup(currencyCode string) (Currency, bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	currency, ok := c.currencies[currencyCode]
+	return currency, ok
+}
+
+func (c *CurrencyCatalog) Exists(currencyCode string) bool {
+	_, ok := c.Lookup(currencyCode)
 	return ok
 }
 
-func CurrencyFullName(currencyCode string) (string, error) {
-	fullName, ok := currencies[currencyCode]
+func (c *CurrencyCatalog) FullName(currencyCode string) (string, error) {
+	currency, ok := c.Lookup(currencyCode)
 	if !ok {
 		return "", errIncorrectCurrency
 	}
-	return fullName, nil
+	return currency.Name, nil
+}
+
+// Similar returns the sorted currency codes in catalog that differ
+// from the given one by a single typo: a changed, missing or extra
+// letter, or two swapped adjacent letters.
+func (c *CurrencyCatalog) Similar(currencyCode string) []string {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	var similar []string
+	for code := range c.currencies {
+		if code != currencyCode && editDistance(code, currencyCode) == 1 {
+			similar = append(similar, code)
+		}
+	}
+	sort.Strings(similar)
+	return similar
+}
+
+// LookupCurrency returns the builtin currency by its code.
+func LookupCurrency(currencyCode string) (Currency, bool) {
+	return builtinCatalog.Lookup(currencyCode)
+}
+
+// LookupCurrencyByNumericCode returns the builtin
+// currency by its ISO 4217 numeric code.
+func LookupCurrencyByNumericCode(numericCode int) (Currency, bool) {
+	currency, ok := builtinCurrenciesByNumericCode[numericCode]
+	return currency, ok
+}
+
+// CurrencyExists reports whether the currency is one of the builtin ones.
+func CurrencyExists(currencyCode string) bool {
+	return builtinCatalog.Exists(currencyCode)
+}
+
+// NormalizeCurrencyCode brings the currency code typed by user
+// to the form used in the catalog: trimmed and in upper case.
+func NormalizeCurrencyCode(currencyCode string) string {
+	return strings.ToUpper(strings.TrimSpace(currencyCode))
+}
+
+// editDistance is the optimal string alignment distance between a and b,
+// the number of insertions, deletions, substitutions and transpositions
+// of adjacent letters needed to turn one string into another.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func min(first int, rest ...int) int {
+	for _, v := range rest {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
+
+// CurrencyFullName returns the name of the builtin currency.
+func CurrencyFullName(currencyCode string) (string, error) {
+	return builtinCatalog.FullName(currencyCode)
 }
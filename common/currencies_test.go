@@ -0,0 +1,106 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCurrencyCode(t *testing.T) {
+	for _, code := range [...]string{"eur", " EUR ", "Eur\n"} {
+		if normalized := NormalizeCurrencyCode(code); normalized != "EUR" {
+			t.Fatalf("%q: expected EUR, got %q", code, normalized)
+		}
+	}
+}
+
+func TestCurrencyCatalogSimilar(t *testing.T) {
+	testCases := [...]struct {
+		code     string
+		expected []string
+	}{
+		{"EUT", []string{"EUR"}},
+		{"UER", []string{"EUR", "YER"}},
+		{"EURO", []string{"EUR"}},
+		{"RUR", []string{"EUR", "MUR", "RUB"}},
+		{"QQQ", nil},
+	}
+	for _, tc := range testCases {
+		if similar := NewCurrencyCatalog().Similar(tc.code); !reflect.DeepEqual(similar, tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.code, tc.expected, similar)
+		}
+	}
+}
+
+func TestBuiltinCurrencies(t *testing.T) {
+	numericCodes := make(map[int]string)
+	for _, c := range builtinCurrencies {
+		if c.Name == "" || c.Kind == "" {
+			t.Fatalf("%s: name and kind are required", c.Code)
+		}
+		if c.MinorUnits != NoMinorUnits && (c.MinorUnits < 0 || c.MinorUnits > 8) {
+			t.Fatalf("%s: incorrect minor units %d", c.Code, c.MinorUnits)
+		}
+		if other, ok := numericCodes[c.NumericCode]; ok && c.NumericCode != 0 {
+			t.Fatalf("%s and %s have the same numeric code %d", c.Code, other, c.NumericCode)
+		}
+		numericCodes[c.NumericCode] = c.Code
+	}
+
+	for _, code := range [...]string{"BTC", "XAU", "XAG"} {
+		if c, _ := LookupCurrency(code); c.Kind == CurrencyFiat {
+			t.Fatalf("%s: expected not to be fiat", code)
+		}
+	}
+	if c, ok := LookupCurrencyByNumericCode(978); !ok || c.Code != "EUR" {
+		t.Fatalf("expected EUR by numeric code 978, got %+v", c)
+	}
+	if c, _ := LookupCurrency("HRK"); !c.Historical {
+		t.Fatal("expected HRK to be historical")
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	testCases := [...]struct {
+		code     string
+		amount   float64
+		expected string
+	}{
+		{"EUR", 0.9059, "€0.91"},
+		{"EUR", -0.1, "-€0.10"},
+		{"EUR", -0.001, "€0.00"},
+		{"JPY", 148.6, "¥149"},
+		{"KWD", 0.30751, "KD 0.308"},
+		{"BTC", 0.0000234, "₿0.00002340"},
+		{"XAU", 0.000426123, "0.000426123 XAU"},
+	}
+	for _, tc := range testCases {
+		c, ok := LookupCurrency(tc.code)
+		if !ok {
+			t.Fatalf("%s: unknown currency", tc.code)
+		}
+		if formatted := c.FormatAmount(tc.amount); formatted != tc.expected {
+			t.Fatalf("%s %v: expected %q, got %q", tc.code, tc.amount, tc.expected, formatted)
+		}
+	}
+}
+
+func TestCurrencyCatalogReplace(t *testing.T) {
+	catalog := NewCurrencyCatalog()
+	catalog.Replace(nil)
+	if !catalog.Exists("GBP") {
+		t.Fatal("expected empty currencies to be ignored")
+	}
+	catalog.Replace(map[string]string{"EUR": "Euro", "XYZ": "Test Coin"})
+	if catalog.Exists("GBP") {
+		t.Fatal("expected currencies to be replaced")
+	}
+	if eur, _ := catalog.Lookup("EUR"); eur.NumericCode != 978 || eur.Symbol != "€" {
+		t.Fatalf("expected metadata of builtin currency to be kept, got %+v", eur)
+	}
+	if xyz, _ := catalog.Lookup("XYZ"); xyz.Name != "Test Coin" || xyz.MinorUnits != NoMinorUnits {
+		t.Fatalf("expected unknown currency to have only name, got %+v", xyz)
+	}
+	if all := catalog.All(); len(all) != 2 || all[0].Code != "EUR" || all[1].Code != "XYZ" {
+		t.Fatalf("expected sorted currencies, got %+v", all)
+	}
+}
@@ -0,0 +1,178 @@
+package common
+
+// builtinCurrencies are the currencies known before the catalog is synced
+// with the rates providers. Numeric codes, minor units and countries are
+// the ones from ISO 4217 and ISO 3166, codes outside of ISO 4217 have no
+// numeric code.
+var builtinCurrencies = []Currency{
+	{Code: "AED", NumericCode: 784, Name: "United Arab Emirates Dirham", MinorUnits: 2, Symbol: "د.إ", Countries: []string{"AE"}, Kind: CurrencyFiat},
+	{Code: "AFN", NumericCode: 971, Name: "Afghan Afghani", MinorUnits: 2, Symbol: "؋", Countries: []string{"AF"}, Kind: CurrencyFiat},
+	{Code: "ALL", NumericCode: 8, Name: "Albanian Lek", MinorUnits: 2, Symbol: "L", Countries: []string{"AL"}, Kind: CurrencyFiat},
+	{Code: "AMD", NumericCode: 51, Name: "Armenian Dram", MinorUnits: 2, Symbol: "֏", Countries: []string{"AM"}, Kind: CurrencyFiat},
+	{Code: "ANG", NumericCode: 532, Name: "Netherlands Antillean Guilder", MinorUnits: 2, Symbol: "ƒ", Countries: []string{"CW", "SX"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "AOA", NumericCode: 973, Name: "Angolan Kwanza", MinorUnits: 2, Symbol: "Kz", Countries: []string{"AO"}, Kind: CurrencyFiat},
+	{Code: "ARS", NumericCode: 32, Name: "Argentine Peso", MinorUnits: 2, Symbol: "$", Countries: []string{"AR"}, Kind: CurrencyFiat},
+	{Code: "AUD", NumericCode: 36, Name: "Australian Dollar", MinorUnits: 2, Symbol: "A$", Countries: []string{"AU", "CC", "CX", "HM", "KI", "NF", "NR", "TV"}, Kind: CurrencyFiat},
+	{Code: "AWG", NumericCode: 533, Name: "Aruban Florin", MinorUnits: 2, Symbol: "ƒ", Countries: []string{"AW"}, Kind: CurrencyFiat},
+	{Code: "AZN", NumericCode: 944, Name: "Azerbaijani Manat", MinorUnits: 2, Symbol: "₼", Countries: []string{"AZ"}, Kind: CurrencyFiat},
+	{Code: "BAM", NumericCode: 977, Name: "Bosnia-Herzegovina Convertible Mark", MinorUnits: 2, Symbol: "KM", Countries: []string{"BA"}, Kind: CurrencyFiat},
+	{Code: "BBD", NumericCode: 52, Name: "Barbadian Dollar", MinorUnits: 2, Symbol: "Bds$", Countries: []string{"BB"}, Kind: CurrencyFiat},
+	{Code: "BDT", NumericCode: 50, Name: "Bangladeshi Taka", MinorUnits: 2, Symbol: "৳", Countries: []string{"BD"}, Kind: CurrencyFiat},
+	{Code: "BGN", NumericCode: 975, Name: "Bulgarian Lev", MinorUnits: 2, Symbol: "лв", Countries: []string{"BG"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "BHD", NumericCode: 48, Name: "Bahraini Dinar", MinorUnits: 3, Symbol: ".د.ب", Countries: []string{"BH"}, Kind: CurrencyFiat},
+	{Code: "BIF", NumericCode: 108, Name: "Burundian Franc", MinorUnits: 0, Symbol: "FBu", Countries: []string{"BI"}, Kind: CurrencyFiat},
+	{Code: "BMD", NumericCode: 60, Name: "Bermudan Dollar", MinorUnits: 2, Symbol: "$", Countries: []string{"BM"}, Kind: CurrencyFiat},
+	{Code: "BND", NumericCode: 96, Name: "Brunei Dollar", MinorUnits: 2, Symbol: "B$", Countries: []string{"BN"}, Kind: CurrencyFiat},
+	{Code: "BOB", NumericCode: 68, Name: "Bolivian Boliviano", MinorUnits: 2, Symbol: "Bs", Countries: []string{"BO"}, Kind: CurrencyFiat},
+	{Code: "BRL", NumericCode: 986, Name: "Brazilian Real", MinorUnits: 2, Symbol: "R$", Countries: []string{"BR"}, Kind: CurrencyFiat},
+	{Code: "BSD", NumericCode: 44, Name: "Bahamian Dollar", MinorUnits: 2, Symbol: "B$", Countries: []string{"BS"}, Kind: CurrencyFiat},
+	{Code: "BTC", Name: "Bitcoin", MinorUnits: 8, Symbol: "₿", Kind: CurrencyCrypto},
+	{Code: "BTN", NumericCode: 64, Name: "Bhutanese Ngultrum", MinorUnits: 2, Symbol: "Nu.", Countries: []string{"BT"}, Kind: CurrencyFiat},
+	{Code: "BWP", NumericCode: 72, Name: "Botswanan Pula", MinorUnits: 2, Symbol: "P", Countries: []string{"BW"}, Kind: CurrencyFiat},
+	{Code: "BYN", NumericCode: 933, Name: "Belarusian Ruble", MinorUnits: 2, Symbol: "Br", Countries: []string{"BY"}, Kind: CurrencyFiat},
+	{Code: "BZD", NumericCode: 84, Name: "Belize Dollar", MinorUnits: 2, Symbol: "BZ$", Countries: []string{"BZ"}, Kind: CurrencyFiat},
+	{Code: "CAD", NumericCode: 124, Name: "Canadian Dollar", MinorUnits: 2, Symbol: "C$", Countries: []string{"CA"}, Kind: CurrencyFiat},
+	{Code: "CDF", NumericCode: 976, Name: "Congolese Franc", MinorUnits: 2, Symbol: "FC", Countries: []string{"CD"}, Kind: CurrencyFiat},
+	{Code: "CHF", NumericCode: 756, Name: "Swiss Franc", MinorUnits: 2, Symbol: "Fr", Countries: []string{"LI", "CH"}, Kind: CurrencyFiat},
+	{Code: "CLF", NumericCode: 990, Name: "Chilean Unit of Account (UF)", MinorUnits: 4, Symbol: "UF", Countries: []string{"CL"}, Kind: CurrencyUnitOfAccount},
+	{Code: "CLP", NumericCode: 152, Name: "Chilean Peso", MinorUnits: 0, Symbol: "$", Countries: []string{"CL"}, Kind: CurrencyFiat},
+	{Code: "CNH", Name: "Chinese Yuan (Offshore)", MinorUnits: 2, Symbol: "¥", Kind: CurrencyFiat},
+	{Code: "CNY", NumericCode: 156, Name: "Chinese Yuan", MinorUnits: 2, Symbol: "¥", Countries: []string{"CN"}, Kind: CurrencyFiat},
+	{Code: "COP", NumericCode: 170, Name: "Colombian Peso", MinorUnits: 2, Symbol: "$", Countries: []string{"CO"}, Kind: CurrencyFiat},
+	{Code: "CRC", NumericCode: 188, Name: "Costa Rican Colón", MinorUnits: 2, Symbol: "₡", Countries: []string{"CR"}, Kind: CurrencyFiat},
+	{Code: "CUC", NumericCode: 931, Name: "Cuban Convertible Peso", MinorUnits: 2, Symbol: "CUC$", Countries: []string{"CU"}, Kind: CurrencyFiat},
+	{Code: "CUP", NumericCode: 192, Name: "Cuban Peso", MinorUnits: 2, Symbol: "₱", Countries: []string{"CU"}, Kind: CurrencyFiat},
+	{Code: "CVE", NumericCode: 132, Name: "Cape Verdean Escudo", MinorUnits: 2, Symbol: "Esc", Countries: []string{"CV"}, Kind: CurrencyFiat},
+	{Code: "CZK", NumericCode: 203, Name: "Czech Republic Koruna", MinorUnits: 2, Symbol: "Kč", Countries: []string{"CZ"}, Kind: CurrencyFiat},
+	{Code: "DJF", NumericCode: 262, Name: "Djiboutian Franc", MinorUnits: 0, Symbol: "Fdj", Countries: []string{"DJ"}, Kind: CurrencyFiat},
+	{Code: "DKK", NumericCode: 208, Name: "Danish Krone", MinorUnits: 2, Symbol: "kr", Countries: []string{"DK", "FO", "GL"}, Kind: CurrencyFiat},
+	{Code: "DOP", NumericCode: 214, Name: "Dominican Peso", MinorUnits: 2, Symbol: "RD$", Countries: []string{"DO"}, Kind: CurrencyFiat},
+	{Code: "DZD", NumericCode: 12, Name: "Algerian Dinar", MinorUnits: 2, Symbol: "د.ج", Countries: []string{"DZ"}, Kind: CurrencyFiat},
+	{Code: "EGP", NumericCode: 818, Name: "Egyptian Pound", MinorUnits: 2, Symbol: "E£", Countries: []string{"EG"}, Kind: CurrencyFiat},
+	{Code: "ERN", NumericCode: 232, Name: "Eritrean Nakfa", MinorUnits: 2, Symbol: "Nfk", Countries: []string{"ER"}, Kind: CurrencyFiat},
+	{Code: "ETB", NumericCode: 230, Name: "Ethiopian Birr", MinorUnits: 2, Symbol: "Br", Countries: []string{"ET"}, Kind: CurrencyFiat},
+	{Code: "EUR", NumericCode: 978, Name: "Euro", MinorUnits: 2, Symbol: "€", Countries: []string{"AD", "AT", "BE", "BG", "CY", "DE", "EE", "ES", "FI", "FR", "GR", "HR", "IE", "IT", "LT", "LU", "LV", "MC", "ME", "MT", "NL", "PT", "SI", "SK", "SM", "VA"}, Kind: CurrencyFiat},
+	{Code: "FJD", NumericCode: 242, Name: "Fijian Dollar", MinorUnits: 2, Symbol: "FJ$", Countries: []string{"FJ"}, Kind: CurrencyFiat},
+	{Code: "FKP", NumericCode: 238, Name: "Falkland Islands Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"FK"}, Kind: CurrencyFiat},
+	{Code: "GBP", NumericCode: 826, Name: "British Pound Sterling", MinorUnits: 2, Symbol: "£", Countries: []string{"GB", "GG", "IM", "JE"}, Kind: CurrencyFiat},
+	{Code: "GEL", NumericCode: 981, Name: "Georgian Lari", MinorUnits: 2, Symbol: "₾", Countries: []string{"GE"}, Kind: CurrencyFiat},
+	{Code: "GGP", Name: "Guernsey Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"GG"}, Kind: CurrencyFiat},
+	{Code: "GHS", NumericCode: 936, Name: "Ghanaian Cedi", MinorUnits: 2, Symbol: "₵", Countries: []string{"GH"}, Kind: CurrencyFiat},
+	{Code: "GIP", NumericCode: 292, Name: "Gibraltar Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"GI"}, Kind: CurrencyFiat},
+	{Code: "GMD", NumericCode: 270, Name: "Gambian Dalasi", MinorUnits: 2, Symbol: "D", Countries: []string{"GM"}, Kind: CurrencyFiat},
+	{Code: "GNF", NumericCode: 324, Name: "Guinean Franc", MinorUnits: 0, Symbol: "FG", Countries: []string{"GN"}, Kind: CurrencyFiat},
+	{Code: "GTQ", NumericCode: 320, Name: "Guatemalan Quetzal", MinorUnits: 2, Symbol: "Q", Countries: []string{"GT"}, Kind: CurrencyFiat},
+	{Code: "GYD", NumericCode: 328, Name: "Guyanaese Dollar", MinorUnits: 2, Symbol: "G$", Countries: []string{"GY"}, Kind: CurrencyFiat},
+	{Code: "HKD", NumericCode: 344, Name: "Hong Kong Dollar", MinorUnits: 2, Symbol: "HK$", Countries: []string{"HK"}, Kind: CurrencyFiat},
+	{Code: "HNL", NumericCode: 340, Name: "Honduran Lempira", MinorUnits: 2, Symbol: "L", Countries: []string{"HN"}, Kind: CurrencyFiat},
+	{Code: "HRK", NumericCode: 191, Name: "Croatian Kuna", MinorUnits: 2, Symbol: "kn", Countries: []string{"HR"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "HTG", NumericCode: 332, Name: "Haitian Gourde", MinorUnits: 2, Symbol: "G", Countries: []string{"HT"}, Kind: CurrencyFiat},
+	{Code: "HUF", NumericCode: 348, Name: "Hungarian Forint", MinorUnits: 2, Symbol: "Ft", Countries: []string{"HU"}, Kind: CurrencyFiat},
+	{Code: "IDR", NumericCode: 360, Name: "Indonesian Rupiah", MinorUnits: 2, Symbol: "Rp", Countries: []string{"ID"}, Kind: CurrencyFiat},
+	{Code: "ILS", NumericCode: 376, Name: "Israeli New Sheqel", MinorUnits: 2, Symbol: "₪", Countries: []string{"IL", "PS"}, Kind: CurrencyFiat},
+	{Code: "IMP", Name: "Manx pound", MinorUnits: 2, Symbol: "£", Countries: []string{"IM"}, Kind: CurrencyFiat},
+	{Code: "INR", NumericCode: 356, Name: "Indian Rupee", MinorUnits: 2, Symbol: "₹", Countries: []string{"IN", "BT"}, Kind: CurrencyFiat},
+	{Code: "IQD", NumericCode: 368, Name: "Iraqi Dinar", MinorUnits: 3, Symbol: "ع.د", Countries: []string{"IQ"}, Kind: CurrencyFiat},
+	{Code: "IRR", NumericCode: 364, Name: "Iranian Rial", MinorUnits: 2, Symbol: "﷼", Countries: []string{"IR"}, Kind: CurrencyFiat},
+	{Code: "ISK", NumericCode: 352, Name: "Icelandic Króna", MinorUnits: 0, Symbol: "kr", Countries: []string{"IS"}, Kind: CurrencyFiat},
+	{Code: "JEP", Name: "Jersey Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"JE"}, Kind: CurrencyFiat},
+	{Code: "JMD", NumericCode: 388, Name: "Jamaican Dollar", MinorUnits: 2, Symbol: "J$", Countries: []string{"JM"}, Kind: CurrencyFiat},
+	{Code: "JOD", NumericCode: 400, Name: "Jordanian Dinar", MinorUnits: 3, Symbol: "JD", Countries: []string{"JO"}, Kind: CurrencyFiat},
+	{Code: "JPY", NumericCode: 392, Name: "Japanese Yen", MinorUnits: 0, Symbol: "¥", Countries: []string{"JP"}, Kind: CurrencyFiat},
+	{Code: "KES", NumericCode: 404, Name: "Kenyan Shilling", MinorUnits: 2, Symbol: "KSh", Countries: []string{"KE"}, Kind: CurrencyFiat},
+	{Code: "KGS", NumericCode: 417, Name: "Kyrgystani Som", MinorUnits: 2, Symbol: "с", Countries: []string{"KG"}, Kind: CurrencyFiat},
+	{Code: "KHR", NumericCode: 116, Name: "Cambodian Riel", MinorUnits: 2, Symbol: "៛", Countries: []string{"KH"}, Kind: CurrencyFiat},
+	{Code: "KMF", NumericCode: 174, Name: "Comorian Franc", MinorUnits: 0, Symbol: "CF", Countries: []string{"KM"}, Kind: CurrencyFiat},
+	{Code: "KPW", NumericCode: 408, Name: "North Korean Won", MinorUnits: 2, Symbol: "₩", Countries: []string{"KP"}, Kind: CurrencyFiat},
+	{Code: "KRW", NumericCode: 410, Name: "South Korean Won", MinorUnits: 0, Symbol: "₩", Countries: []string{"KR"}, Kind: CurrencyFiat},
+	{Code: "KWD", NumericCode: 414, Name: "Kuwaiti Dinar", MinorUnits: 3, Symbol: "KD", Countries: []string{"KW"}, Kind: CurrencyFiat},
+	{Code: "KYD", NumericCode: 136, Name: "Cayman Islands Dollar", MinorUnits: 2, Symbol: "CI$", Countries: []string{"KY"}, Kind: CurrencyFiat},
+	{Code: "KZT", NumericCode: 398, Name: "Kazakhstani Tenge", MinorUnits: 2, Symbol: "₸", Countries: []string{"KZ"}, Kind: CurrencyFiat},
+	{Code: "LAK", NumericCode: 418, Name: "Laotian Kip", MinorUnits: 2, Symbol: "₭", Countries: []string{"LA"}, Kind: CurrencyFiat},
+	{Code: "LBP", NumericCode: 422, Name: "Lebanese Pound", MinorUnits: 2, Symbol: "ل.ل", Countries: []string{"LB"}, Kind: CurrencyFiat},
+	{Code: "LKR", NumericCode: 144, Name: "Sri Lankan Rupee", MinorUnits: 2, Symbol: "Rs", Countries: []string{"LK"}, Kind: CurrencyFiat},
+	{Code: "LRD", NumericCode: 430, Name: "Liberian Dollar", MinorUnits: 2, Symbol: "L$", Countries: []string{"LR"}, Kind: CurrencyFiat},
+	{Code: "LSL", NumericCode: 426, Name: "Lesotho Loti", MinorUnits: 2, Symbol: "L", Countries: []string{"LS"}, Kind: CurrencyFiat},
+	{Code: "LYD", NumericCode: 434, Name: "Libyan Dinar", MinorUnits: 3, Symbol: "LD", Countries: []string{"LY"}, Kind: CurrencyFiat},
+	{Code: "MAD", NumericCode: 504, Name: "Moroccan Dirham", MinorUnits: 2, Symbol: "DH", Countries: []string{"MA", "EH"}, Kind: CurrencyFiat},
+	{Code: "MDL", NumericCode: 498, Name: "Moldovan Leu", MinorUnits: 2, Symbol: "L", Countries: []string{"MD"}, Kind: CurrencyFiat},
+	{Code: "MGA", NumericCode: 969, Name: "Malagasy Ariary", MinorUnits: 2, Symbol: "Ar", Countries: []string{"MG"}, Kind: CurrencyFiat},
+	{Code: "MKD", NumericCode: 807, Name: "Macedonian Denar", MinorUnits: 2, Symbol: "ден", Countries: []string{"MK"}, Kind: CurrencyFiat},
+	{Code: "MMK", NumericCode: 104, Name: "Myanma Kyat", MinorUnits: 2, Symbol: "K", Countries: []string{"MM"}, Kind: CurrencyFiat},
+	{Code: "MNT", NumericCode: 496, Name: "Mongolian Tugrik", MinorUnits: 2, Symbol: "₮", Countries: []string{"MN"}, Kind: CurrencyFiat},
+	{Code: "MOP", NumericCode: 446, Name: "Macanese Pataca", MinorUnits: 2, Symbol: "MOP$", Countries: []string{"MO"}, Kind: CurrencyFiat},
+	{Code: "MRU", NumericCode: 929, Name: "Mauritanian Ouguiya", MinorUnits: 2, Symbol: "UM", Countries: []string{"MR"}, Kind: CurrencyFiat},
+	{Code: "MUR", NumericCode: 480, Name: "Mauritian Rupee", MinorUnits: 2, Symbol: "₨", Countries: []string{"MU"}, Kind: CurrencyFiat},
+	{Code: "MVR", NumericCode: 462, Name: "Maldivian Rufiyaa", MinorUnits: 2, Symbol: "Rf", Countries: []string{"MV"}, Kind: CurrencyFiat},
+	{Code: "MWK", NumericCode: 454, Name: "Malawian Kwacha", MinorUnits: 2, Symbol: "MK", Countries: []string{"MW"}, Kind: CurrencyFiat},
+	{Code: "MXN", NumericCode: 484, Name: "Mexican Peso", MinorUnits: 2, Symbol: "Mex$", Countries: []string{"MX"}, Kind: CurrencyFiat},
+	{Code: "MYR", NumericCode: 458, Name: "Malaysian Ringgit", MinorUnits: 2, Symbol: "RM", Countries: []string{"MY"}, Kind: CurrencyFiat},
+	{Code: "MZN", NumericCode: 943, Name: "Mozambican Metical", MinorUnits: 2, Symbol: "MT", Countries: []string{"MZ"}, Kind: CurrencyFiat},
+	{Code: "NAD", NumericCode: 516, Name: "Namibian Dollar", MinorUnits: 2, Symbol: "N$", Countries: []string{"NA"}, Kind: CurrencyFiat},
+	{Code: "NGN", NumericCode: 566, Name: "Nigerian Naira", MinorUnits: 2, Symbol: "₦", Countries: []string{"NG"}, Kind: CurrencyFiat},
+	{Code: "NIO", NumericCode: 558, Name: "Nicaraguan Córdoba", MinorUnits: 2, Symbol: "C$", Countries: []string{"NI"}, Kind: CurrencyFiat},
+	{Code: "NOK", NumericCode: 578, Name: "Norwegian Krone", MinorUnits: 2, Symbol: "kr", Countries: []string{"NO", "SJ", "BV"}, Kind: CurrencyFiat},
+	{Code: "NPR", NumericCode: 524, Name: "Nepalese Rupee", MinorUnits: 2, Symbol: "Rs", Countries: []string{"NP"}, Kind: CurrencyFiat},
+	{Code: "NZD", NumericCode: 554, Name: "New Zealand Dollar", MinorUnits: 2, Symbol: "NZ$", Countries: []string{"NZ", "CK", "NU", "PN", "TK"}, Kind: CurrencyFiat},
+	{Code: "OMR", NumericCode: 512, Name: "Omani Rial", MinorUnits: 3, Symbol: "ر.ع.", Countries: []string{"OM"}, Kind: CurrencyFiat},
+	{Code: "PAB", NumericCode: 590, Name: "Panamanian Balboa", MinorUnits: 2, Symbol: "B/.", Countries: []string{"PA"}, Kind: CurrencyFiat},
+	{Code: "PEN", NumericCode: 604, Name: "Peruvian Nuevo Sol", MinorUnits: 2, Symbol: "S/", Countries: []string{"PE"}, Kind: CurrencyFiat},
+	{Code: "PGK", NumericCode: 598, Name: "Papua New Guinean Kina", MinorUnits: 2, Symbol: "K", Countries: []string{"PG"}, Kind: CurrencyFiat},
+	{Code: "PHP", NumericCode: 608, Name: "Philippine Peso", MinorUnits: 2, Symbol: "₱", Countries: []string{"PH"}, Kind: CurrencyFiat},
+	{Code: "PKR", NumericCode: 586, Name: "Pakistani Rupee", MinorUnits: 2, Symbol: "Rs", Countries: []string{"PK"}, Kind: CurrencyFiat},
+	{Code: "PLN", NumericCode: 985, Name: "Polish Zloty", MinorUnits: 2, Symbol: "zł", Countries: []string{"PL"}, Kind: CurrencyFiat},
+	{Code: "PYG", NumericCode: 600, Name: "Paraguayan Guarani", MinorUnits: 0, Symbol: "₲", Countries: []string{"PY"}, Kind: CurrencyFiat},
+	{Code: "QAR", NumericCode: 634, Name: "Qatari Rial", MinorUnits: 2, Symbol: "QR", Countries: []string{"QA"}, Kind: CurrencyFiat},
+	{Code: "RON", NumericCode: 946, Name: "Romanian Leu", MinorUnits: 2, Symbol: "lei", Countries: []string{"RO"}, Kind: CurrencyFiat},
+	{Code: "RSD", NumericCode: 941, Name: "Serbian Dinar", MinorUnits: 2, Symbol: "дин.", Countries: []string{"RS"}, Kind: CurrencyFiat},
+	{Code: "RUB", NumericCode: 643, Name: "Russian Ruble", MinorUnits: 2, Symbol: "₽", Countries: []string{"RU"}, Kind: CurrencyFiat},
+	{Code: "RWF", NumericCode: 646, Name: "Rwandan Franc", MinorUnits: 0, Symbol: "FRw", Countries: []string{"RW"}, Kind: CurrencyFiat},
+	{Code: "SAR", NumericCode: 682, Name: "Saudi Riyal", MinorUnits: 2, Symbol: "SR", Countries: []string{"SA"}, Kind: CurrencyFiat},
+	{Code: "SBD", NumericCode: 90, Name: "Solomon Islands Dollar", MinorUnits: 2, Symbol: "SI$", Countries: []string{"SB"}, Kind: CurrencyFiat},
+	{Code: "SCR", NumericCode: 690, Name: "Seychellois Rupee", MinorUnits: 2, Symbol: "SRe", Countries: []string{"SC"}, Kind: CurrencyFiat},
+	{Code: "SDG", NumericCode: 938, Name: "Sudanese Pound", MinorUnits: 2, Symbol: "£SD", Countries: []string{"SD"}, Kind: CurrencyFiat},
+	{Code: "SEK", NumericCode: 752, Name: "Swedish Krona", MinorUnits: 2, Symbol: "kr", Countries: []string{"SE"}, Kind: CurrencyFiat},
+	{Code: "SGD", NumericCode: 702, Name: "Singapore Dollar", MinorUnits: 2, Symbol: "S$", Countries: []string{"SG"}, Kind: CurrencyFiat},
+	{Code: "SHP", NumericCode: 654, Name: "Saint Helena Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"SH"}, Kind: CurrencyFiat},
+	{Code: "SLL", NumericCode: 694, Name: "Sierra Leonean Leone", MinorUnits: 2, Symbol: "Le", Countries: []string{"SL"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "SOS", NumericCode: 706, Name: "Somali Shilling", MinorUnits: 2, Symbol: "Sh", Countries: []string{"SO"}, Kind: CurrencyFiat},
+	{Code: "SRD", NumericCode: 968, Name: "Surinamese Dollar", MinorUnits: 2, Symbol: "$", Countries: []string{"SR"}, Kind: CurrencyFiat},
+	{Code: "SSP", NumericCode: 728, Name: "South Sudanese Pound", MinorUnits: 2, Symbol: "£", Countries: []string{"SS"}, Kind: CurrencyFiat},
+	{Code: "STD", NumericCode: 678, Name: "São Tomé and Príncipe Dobra (pre-2018)", MinorUnits: 2, Symbol: "Db", Countries: []string{"ST"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "STN", NumericCode: 930, Name: "São Tomé and Príncipe Dobra", MinorUnits: 2, Symbol: "Db", Countries: []string{"ST"}, Kind: CurrencyFiat},
+	{Code: "SVC", NumericCode: 222, Name: "Salvadoran Colón", MinorUnits: 2, Symbol: "₡", Countries: []string{"SV"}, Kind: CurrencyFiat},
+	{Code: "SYP", NumericCode: 760, Name: "Syrian Pound", MinorUnits: 2, Symbol: "£S", Countries: []string{"SY"}, Kind: CurrencyFiat},
+	{Code: "SZL", NumericCode: 748, Name: "Swazi Lilangeni", MinorUnits: 2, Symbol: "E", Countries: []string{"SZ"}, Kind: CurrencyFiat},
+	{Code: "THB", NumericCode: 764, Name: "Thai Baht", MinorUnits: 2, Symbol: "฿", Countries: []string{"TH"}, Kind: CurrencyFiat},
+	{Code: "TJS", NumericCode: 972, Name: "Tajikistani Somoni", MinorUnits: 2, Symbol: "SM", Countries: []string{"TJ"}, Kind: CurrencyFiat},
+	{Code: "TMT", NumericCode: 934, Name: "Turkmenistani Manat", MinorUnits: 2, Symbol: "m", Countries: []string{"TM"}, Kind: CurrencyFiat},
+	{Code: "TND", NumericCode: 788, Name: "Tunisian Dinar", MinorUnits: 3, Symbol: "DT", Countries: []string{"TN"}, Kind: CurrencyFiat},
+	{Code: "TOP", NumericCode: 776, Name: "Tongan Pa'anga", MinorUnits: 2, Symbol: "T$", Countries: []string{"TO"}, Kind: CurrencyFiat},
+	{Code: "TRY", NumericCode: 949, Name: "Turkish Lira", MinorUnits: 2, Symbol: "₺", Countries: []string{"TR"}, Kind: CurrencyFiat},
+	{Code: "TTD", NumericCode: 780, Name: "Trinidad and Tobago Dollar", MinorUnits: 2, Symbol: "TT$", Countries: []string{"TT"}, Kind: CurrencyFiat},
+	{Code: "TWD", NumericCode: 901, Name: "New Taiwan Dollar", MinorUnits: 2, Symbol: "NT$", Countries: []string{"TW"}, Kind: CurrencyFiat},
+	{Code: "TZS", NumericCode: 834, Name: "Tanzanian Shilling", MinorUnits: 2, Symbol: "TSh", Countries: []string{"TZ"}, Kind: CurrencyFiat},
+	{Code: "UAH", NumericCode: 980, Name: "Ukrainian Hryvnia", MinorUnits: 2, Symbol: "₴", Countries: []string{"UA"}, Kind: CurrencyFiat},
+	{Code: "UGX", NumericCode: 800, Name: "Ugandan Shilling", MinorUnits: 0, Symbol: "USh", Countries: []string{"UG"}, Kind: CurrencyFiat},
+	{Code: "USD", NumericCode: 840, Name: "United States Dollar", MinorUnits: 2, Symbol: "$", Countries: []string{"US", "AS", "BQ", "EC", "FM", "GU", "IO", "MH", "MP", "PR", "PW", "SV", "TC", "TL", "UM", "VG", "VI"}, Kind: CurrencyFiat},
+	{Code: "UYU", NumericCode: 858, Name: "Uruguayan Peso", MinorUnits: 2, Symbol: "$U", Countries: []string{"UY"}, Kind: CurrencyFiat},
+	{Code: "UZS", NumericCode: 860, Name: "Uzbekistan Som", MinorUnits: 2, Symbol: "soʻm", Countries: []string{"UZ"}, Kind: CurrencyFiat},
+	{Code: "VEF", NumericCode: 937, Name: "Venezuelan Bolívar Fuerte (Old)", MinorUnits: 2, Symbol: "Bs", Countries: []string{"VE"}, Kind: CurrencyFiat, Historical: true},
+	{Code: "VES", NumericCode: 928, Name: "Venezuelan Bolívar Soberano", MinorUnits: 2, Symbol: "Bs.S", Countries: []string{"VE"}, Kind: CurrencyFiat},
+	{Code: "VND", NumericCode: 704, Name: "Vietnamese Dong", MinorUnits: 0, Symbol: "₫", Countries: []string{"VN"}, Kind: CurrencyFiat},
+	{Code: "VUV", NumericCode: 548, Name: "Vanuatu Vatu", MinorUnits: 0, Symbol: "VT", Countries: []string{"VU"}, Kind: CurrencyFiat},
+	{Code: "WST", NumericCode: 882, Name: "Samoan Tala", MinorUnits: 2, Symbol: "WS$", Countries: []string{"WS"}, Kind: CurrencyFiat},
+	{Code: "XAF", NumericCode: 950, Name: "CFA Franc BEAC", MinorUnits: 0, Symbol: "FCFA", Countries: []string{"CM", "CF", "TD", "CG", "GQ", "GA"}, Kind: CurrencyFiat},
+	{Code: "XAG", NumericCode: 961, Name: "Silver Ounce", MinorUnits: NoMinorUnits, Kind: CurrencyMetal},
+	{Code: "XAU", NumericCode: 959, Name: "Gold Ounce", MinorUnits: NoMinorUnits, Kind: CurrencyMetal},
+	{Code: "XCD", NumericCode: 951, Name: "East Caribbean Dollar", MinorUnits: 2, Symbol: "EC$", Countries: []string{"AG", "AI", "DM", "GD", "KN", "LC", "MS", "VC"}, Kind: CurrencyFiat},
+	{Code: "XDR", NumericCode: 960, Name: "Special Drawing Rights", MinorUnits: NoMinorUnits, Kind: CurrencyUnitOfAccount},
+	{Code: "XOF", NumericCode: 952, Name: "CFA Franc BCEAO", MinorUnits: 0, Symbol: "CFA", Countries: []string{"BJ", "BF", "CI", "GW", "ML", "NE", "SN", "TG"}, Kind: CurrencyFiat},
+	{Code: "XPD", NumericCode: 964, Name: "Palladium Ounce", MinorUnits: NoMinorUnits, Kind: CurrencyMetal},
+	{Code: "XPF", NumericCode: 953, Name: "CFP Franc", MinorUnits: 0, Symbol: "₣", Countries: []string{"PF", "NC", "WF"}, Kind: CurrencyFiat},
+	{Code: "XPT", NumericCode: 962, Name: "Platinum Ounce", MinorUnits: NoMinorUnits, Kind: CurrencyMetal},
+	{Code: "YER", NumericCode: 886, Name: "Yemeni Rial", MinorUnits: 2, Symbol: "﷼", Countries: []string{"YE"}, Kind: CurrencyFiat},
+	{Code: "ZAR", NumericCode: 710, Name: "South African Rand", MinorUnits: 2, Symbol: "R", Countries: []string{"ZA", "LS", "NA", "SZ"}, Kind: CurrencyFiat},
+	{Code: "ZMW", NumericCode: 967, Name: "Zambian Kwacha", MinorUnits: 2, Symbol: "ZK", Countries: []string{"ZM"}, Kind: CurrencyFiat},
+	{Code: "ZWL", NumericCode: 932, Name: "Zimbabwean Dollar", MinorUnits: 2, Symbol: "Z$", Countries: []string{"ZW"}, Kind: CurrencyFiat, Historical: true},
+}
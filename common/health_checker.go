@@ -0,0 +1,35 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// HealthChecker is a simple circuit breaker: once something is set
+// unavailable it is considered unavailable until the cooldown passes.
+type HealthChecker struct {
+	isAvailable              bool
+	lastUnavailableTimestamp time.Time
+	cooldown                 time.Duration
+	m                        sync.Mutex
+}
+
+func NewHealthChecker(cooldown time.Duration) *HealthChecker {
+	return &HealthChecker{isAvailable: true, cooldown: cooldown}
+}
+
+func (hc *HealthChecker) SetUnavailable() {
+	hc.m.Lock()
+	defer hc.m.Unlock()
+	hc.isAvailable = false
+	hc.lastUnavailableTimestamp = time.Now()
+}
+
+func (hc *HealthChecker) IsAvailable() bool {
+	hc.m.Lock()
+	defer hc.m.Unlock()
+	if time.Since(hc.lastUnavailableTimestamp) > hc.cooldown {
+		hc.isAvailable = true
+	}
+	return hc.isAvailable
+}
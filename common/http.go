@@ -0,0 +1,15 @@
+package common
+
+import (
+	"context"
+	"net/http"
+)
+
+// HttpGet is http.Client.Get aborted when ctx is done.
+func HttpGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
@@ -2,12 +2,14 @@ package common
 
 import (
 	"log"
-
-	"github.com/Ghytro/ab_interview/config"
 )
 
-func LogIfVerbose(message interface{}) {
-	if config.Config.IsVerbose {
+type Logger struct {
+	Verbose bool
+}
+
+func (l *Logger) LogIfVerbose(message interface{}) {
+	if l.Verbose {
 		log.Println(message)
 	}
 }
@@ -2,17 +2,12 @@ package common
 
 import (
 	"strings"
-	"sync"
 	"time"
 )
 
-type redisHealth struct {
-	isAvailable              bool
-	lastUnavailableTimestamp time.Time
-	m                        sync.Mutex
-}
-
-var rh = &redisHealth{true, time.Time{}, sync.Mutex{}}
+// RedisHealthCheckCooldown is how long redis is considered
+// unavailable after a bad connection.
+const RedisHealthCheckCooldown = time.Minute
 
 func IsBadRedisConnectionErr(err error) bool {
 	if err == nil {
@@ -20,19 +15,3 @@ func IsBadRedisConnectionErr(err error) bool {
 	}
 	return strings.HasPrefix(err.Error(), "dial tcp")
 }
-
-func SetRedisUnavailable() {
-	rh.m.Lock()
-	defer rh.m.Unlock()
-	rh.isAvailable = false
-	rh.lastUnavailableTimestamp = time.Now()
-}
-
-func IsRedisAvailable() bool {
-	rh.m.Lock()
-	defer rh.m.Unlock()
-	if time.Since(rh.lastUnavailableTimestamp) > time.Minute {
-		rh.isAvailable = true
-	}
-	return rh.isAvailable
-}
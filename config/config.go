@@ -1,47 +1,298 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
-	"log"
 	"os"
+	"time"
 )
 
-var errIncorrectLimit = errors.New("incorrect value of the limit")
+const (
+	ThresholdModeAbsolute = "absolute"
+	ThresholdModePercent  = "percent"
+)
+
+// Rates are given in units of currency per one unit of base currency,
+// so the growing rate means that the currency became cheaper.
+// DirectionStrength considers the currency getting more expensive
+// relative to the base as "rich", DirectionQuote considers the rate
+// itself growing as "rich".
+const (
+	DirectionStrength = "strength"
+	DirectionQuote    = "quote"
+)
 
 type ServiceConfig struct {
 	Port                     int               `json:"port"`
-	OpenExchangeApiToken     string            `json:"openexchange_api_token"`
+	OpenExchangeApiToken     string            `json:"openexchange_api_token" secret:"true"`
 	OpenExchangeBaseUrl      string            `json:"openexchange_base_url"`
 	TenorBaseUrl             string            `json:"tenor_base_url"`
-	TenorApiToken            string            `json:"tenor_api_token"`
+	TenorApiToken            string            `json:"tenor_api_token" secret:"true"`
 	TenorMediaStorageBaseUrl string            `json:"tenor_media_storage_base_url"`
 	TenorSearchQueryLimit    int               `json:"tenor_search_query_limit"`
+	GifProvider              string            `json:"gif_provider"`
+	Giphy                    GiphyConfig       `json:"giphy"`
+	LocalGifs                LocalGifsConfig   `json:"local_gifs"`
+	Cache                    CacheConfig       `json:"cache"`
 	RedisClientOptions       RedisClientConfig `json:"redis_client_options"`
 	BaseCurrencyId           string            `json:"base_currency_id"`
+	RatesProviders           []string          `json:"rates_providers"`
+	RatesProviderCooldown    Duration          `json:"rates_provider_cooldown"`
+	Ecb                      EcbConfig         `json:"ecb"`
+	Cbr                      CbrConfig         `json:"cbr"`
+	CsvRates                 CsvRatesConfig    `json:"csv_rates"`
+	Verdict                  VerdictConfig     `json:"verdict"`
+	Server                   ServerConfig      `json:"server"`
+	HttpClient               HttpClientConfig  `json:"http_client"`
+	Currencies               CurrenciesConfig  `json:"currencies"`
 	IsVerbose                bool              `json:"verbose"`
 }
 
+// ServerConfig holds the timeouts of http server, they take
+// effect only after restart, except RequestTimeout. ShutdownTimeout
+// limits the time the requests in progress are waited for on shutdown.
+// RequestTimeout limits the time spent on upstream apis per request.
+type ServerConfig struct {
+	ReadHeaderTimeout Duration `json:"read_header_timeout"`
+	ReadTimeout       Duration `json:"read_timeout"`
+	WriteTimeout      Duration `json:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout"`
+	RequestTimeout    Duration `json:"request_timeout"`
+}
+
+// HttpClientConfig configures the client shared by all upstream apis.
+type HttpClientConfig struct {
+	Timeout Duration `json:"timeout"`
+}
+
+// CurrenciesConfig configures the sync of the currency catalog with the
+// rates providers listing currencies. The listing is cached in redis
+// for SyncInterval, so all instances share it.
+type CurrenciesConfig struct {
+	SyncInterval Duration `json:"sync_interval"`
+}
+
+// VerdictConfig describes how the rate change turns into a verdict.
+// Changes not exceeding StableThreshold (either in units of compared
+// value or in percents, depending on StableThresholdMode) are considered
+// stable. Direction is the default one, it can be overridden per request.
+type VerdictConfig struct {
+	Direction           string  `json:"direction"`
+	StableThreshold     float64 `json:"stable_threshold"`
+	StableThresholdMode string  `json:"stable_threshold_mode"`
+	RichSearchQuery     string  `json:"rich_search_query"`
+	BrokeSearchQuery    string  `json:"broke_search_query"`
+	StableSearchQuery   string  `json:"stable_search_query"`
+}
+
+type EcbConfig struct {
+	BaseUrl string `json:"base_url"`
+}
+
+type CbrConfig struct {
+	BaseUrl string `json:"base_url"`
+}
+
+type CsvRatesConfig struct {
+	Path           string `json:"path"`
+	BaseCurrencyId string `json:"base_currency_id"`
+}
+
+type GiphyConfig struct {
+	ApiToken         string `json:"api_token" secret:"true"`
+	BaseUrl          string `json:"base_url"`
+	MediaBaseUrl     string `json:"media_base_url"`
+	SearchQueryLimit int    `json:"search_query_limit"`
+}
+
+type LocalGifsConfig struct {
+	Dir string `json:"dir"`
+}
+
+// CacheConfig selects where the upstream responses are cached: "redis"
+// shared by all instances, "memory" of the process keeping at most
+// MemorySize values of at most MemoryMaxBytes in total, or "none"
+// to not cache at all.
+type CacheConfig struct {
+	Type           string           `json:"type"`
+	MemorySize     int              `json:"memory_size"`
+	MemoryMaxBytes int              `json:"memory_max_bytes"`
+	L1             L1CacheConfig    `json:"l1"`
+	TTL            CacheTTLConfig   `json:"ttl"`
+	Media          MediaCacheConfig `json:"media"`
+	Lock           LockConfig       `json:"lock"`
+}
+
+// LockConfig enables coalescing of the upstream fetches across the
+// instances sharing redis: only the instance holding the lock of the
+// key fetches it, for at most TTL, the others wait for it to be cached.
+type LockConfig struct {
+	Enabled bool     `json:"enabled"`
+	TTL     Duration `json:"ttl"`
+}
+
+// MediaCacheConfig is the budget of gifs in redis: at most MaxBytes
+// in total, the least recently used ones are evicted first, the gifs
+// larger than MaxBlobSize are not cached.
+type MediaCacheConfig struct {
+	MaxBytes    int `json:"max_bytes"`
+	MaxBlobSize int `json:"max_blob_size"`
+}
+
+// CacheTTLConfig is how long the cached values are kept. Zero means until
+// evicted for HistoricalRates and Gifs, while CurrentRates and GifIds
+// must be updated from time to time, so zero takes the default for them.
+// Rates of the past days don't change and are kept until evicted by
+// default, the rates of the current day are updated during the day.
+type CacheTTLConfig struct {
+	CurrentRates    Duration `json:"current_rates"`
+	HistoricalRates Duration `json:"historical_rates"`
+	GifIds          Duration `json:"gif_ids"`
+	Gifs            Duration `json:"gifs"`
+}
+
+// L1CacheConfig is the in-memory cache in front of redis keeping at most
+// MaxBytes of rates tables and gifs for at most TTL, so the hot values
+// are served without going to redis and while it is down.
+type L1CacheConfig struct {
+	Disabled bool     `json:"disabled"`
+	MaxBytes int      `json:"max_bytes"`
+	TTL      Duration `json:"ttl"`
+}
+
 type RedisClientConfig struct {
 	DB       int    `json:"db"`
-	Password string `json:"password"`
+	Password string `json:"password" secret:"true"`
 	Addr     string `json:"addr"`
 }
 
-var Config = new(ServiceConfig)
+// IsValidDirection reports whether direction is a known one,
+// empty direction means the default one and is valid too.
+func IsValidDirection(direction string) bool {
+	switch direction {
+	case "", DirectionStrength, DirectionQuote:
+		return true
+	}
+	return false
+}
 
-func init() {
-	confContent, err := os.ReadFile("config/config.json")
-	if err != nil {
-		confContent, err = os.ReadFile("../config/config.json")
-		if err != nil {
-			log.Fatal(err)
+// Load reads config from the file at the given path, overrides it
+// with the environment variables, fills the omitted optional parameters
+// with default values and checks them. The file may be json, yaml or toml,
+// the format is chosen by its extension. With empty path the config
+// is read from the environment variables only.
+func Load(path string) (*ServiceConfig, error) {
+	conf := new(ServiceConfig)
+	if path != "" {
+		if err := readFile(path, conf); err != nil {
+			return nil, err
 		}
 	}
-	if err := json.Unmarshal(confContent, Config); err != nil {
-		log.Fatal(err)
+	if err := overrideFromEnv(conf, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := resolveSecrets(conf); err != nil {
+		return nil, err
+	}
+	conf.SetDefaults()
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// SetDefaults fills the omitted optional parameters with default values.
+func (c *ServiceConfig) SetDefaults() {
+	if c.GifProvider == "" {
+		c.GifProvider = "tenor"
+	}
+	if c.Giphy.BaseUrl == "" {
+		c.Giphy.BaseUrl = "https://api.giphy.com/v1/"
+	}
+	if c.Giphy.MediaBaseUrl == "" {
+		c.Giphy.MediaBaseUrl = "https://media.giphy.com/media/"
+	}
+	if c.Giphy.SearchQueryLimit == 0 {
+		c.Giphy.SearchQueryLimit = 50
+	}
+	if c.Cache.Type == "" {
+		c.Cache.Type = "redis"
+	}
+	if c.Cache.MemorySize == 0 {
+		c.Cache.MemorySize = 10000
+	}
+	if c.Cache.MemoryMaxBytes == 0 {
+		c.Cache.MemoryMaxBytes = 64 << 20
+	}
+	if c.Cache.L1.MaxBytes == 0 {
+		c.Cache.L1.MaxBytes = 32 << 20
+	}
+	if c.Cache.L1.TTL == 0 {
+		c.Cache.L1.TTL = Duration(time.Minute)
+	}
+	if c.Cache.Media.MaxBytes == 0 {
+		c.Cache.Media.MaxBytes = 256 << 20
+	}
+	if c.Cache.Media.MaxBlobSize == 0 {
+		c.Cache.Media.MaxBlobSize = 5 << 20
+	}
+	if c.Cache.Lock.TTL == 0 {
+		c.Cache.Lock.TTL = Duration(10 * time.Second)
+	}
+	if c.Cache.TTL.CurrentRates == 0 {
+		c.Cache.TTL.CurrentRates = Duration(10 * time.Minute)
+	}
+	if c.Cache.TTL.GifIds == 0 {
+		c.Cache.TTL.GifIds = Duration(24 * time.Hour)
+	}
+	if len(c.RatesProviders) == 0 {
+		c.RatesProviders = []string{"openexchange"}
+	}
+	if c.RatesProviderCooldown == 0 {
+		c.RatesProviderCooldown = Duration(time.Minute)
+	}
+	if c.Ecb.BaseUrl == "" {
+		c.Ecb.BaseUrl = "https://www.ecb.europa.eu/stats/eurofxref/"
+	}
+	if c.Cbr.BaseUrl == "" {
+		c.Cbr.BaseUrl = "https://www.cbr.ru/scripts/"
+	}
+	if c.Verdict.Direction == "" {
+		c.Verdict.Direction = DirectionStrength
+	}
+	if c.Verdict.StableThresholdMode == "" {
+		c.Verdict.StableThresholdMode = ThresholdModeAbsolute
+	}
+	if c.Verdict.RichSearchQuery == "" {
+		c.Verdict.RichSearchQuery = "rich"
+	}
+	if c.Verdict.BrokeSearchQuery == "" {
+		c.Verdict.BrokeSearchQuery = "broke"
+	}
+	if c.Verdict.StableSearchQuery == "" {
+		c.Verdict.StableSearchQuery = "meh"
+	}
+	if c.Server.ReadHeaderTimeout == 0 {
+		c.Server.ReadHeaderTimeout = Duration(5 * time.Second)
+	}
+	if c.Server.ReadTimeout == 0 {
+		c.Server.ReadTimeout = Duration(10 * time.Second)
+	}
+	if c.Server.WriteTimeout == 0 {
+		c.Server.WriteTimeout = Duration(30 * time.Second)
+	}
+	if c.Server.IdleTimeout == 0 {
+		c.Server.IdleTimeout = Duration(2 * time.Minute)
+	}
+	if c.Server.ShutdownTimeout == 0 {
+		c.Server.ShutdownTimeout = Duration(30 * time.Second)
+	}
+	if c.Server.RequestTimeout == 0 {
+		c.Server.RequestTimeout = Duration(20 * time.Second)
+	}
+	if c.HttpClient.Timeout == 0 {
+		c.HttpClient.Timeout = Duration(10 * time.Second)
 	}
-	if Config.TenorSearchQueryLimit < 0 {
-		log.Fatal(errIncorrectLimit)
+	if c.Currencies.SyncInterval == 0 {
+		c.Currencies.SyncInterval = Duration(12 * time.Hour)
 	}
 }
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFormats(t *testing.T) {
+	for _, format := range [...]string{"yaml", "toml"} {
+		conf, err := Load(filepath.Join("testdata", "config."+format))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if conf.OpenExchangeApiToken != format+" openexchange token" ||
+			conf.TenorApiToken != format+" tenor token" ||
+			conf.RedisClientOptions.Addr != "127.0.0.1:6379" ||
+			!reflect.DeepEqual(conf.RatesProviders, []string{"ecb", "openexchange"}) ||
+			conf.Verdict.StableThreshold != 0.5 ||
+			conf.Verdict.StableThresholdMode != ThresholdModePercent {
+			t.Fatalf("%s: config was read incorrectly: %+v", format, conf)
+		}
+		// omitted parameters are filled with defaults
+		if conf.Verdict.Direction != DirectionStrength || conf.GifProvider != "tenor" {
+			t.Fatalf("%s: defaults were not set: %+v", format, conf)
+		}
+	}
+
+	if _, err := Load("config.ini"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected error for missing file, got %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte("port=8080"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); !errors.Is(err, errUnsupportedConfigFormat) {
+		t.Fatalf("expected %v, got %v", errUnsupportedConfigFormat, err)
+	}
+}
+
+func TestLoadEnvOverride(t *testing.T) {
+	t.Setenv("RICHORBROKE_TENOR_API_TOKEN", "env tenor token")
+	t.Setenv("RICHORBROKE_REDIS_CLIENT_OPTIONS_ADDR", "redis:6379")
+	t.Setenv("RICHORBROKE_RATES_PROVIDERS", "cbr, ecb")
+	t.Setenv("RICHORBROKE_VERDICT_STABLE_THRESHOLD", "1.5")
+	t.Setenv("RICHORBROKE_VERBOSE", "true")
+	conf, err := Load("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.TenorApiToken != "env tenor token" ||
+		conf.RedisClientOptions.Addr != "redis:6379" ||
+		!reflect.DeepEqual(conf.RatesProviders, []string{"cbr", "ecb"}) ||
+		conf.Verdict.StableThreshold != 1.5 ||
+		!conf.IsVerbose {
+		t.Fatalf("config was not overridden: %+v", conf)
+	}
+	// parameters without variables are kept from the file
+	if conf.OpenExchangeApiToken != "open exchange api token" || conf.Port != 8080 {
+		t.Fatalf("config from file was lost: %+v", conf)
+	}
+
+	t.Setenv("RICHORBROKE_PORT", "http")
+	if _, err := Load("config.json"); !errors.Is(err, errIncorrectEnvValue) {
+		t.Fatalf("expected %v, got %v", errIncorrectEnvValue, err)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secrets := map[string]string{
+		"openexchange": "openexchange secret\n",
+		"tenor":        "tenor secret\n",
+	}
+	for name, secret := range secrets {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(secret), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	confPath := filepath.Join(dir, "config.json")
+	confContent, err := os.ReadFile("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	confContent = bytes.Replace(
+		confContent,
+		[]byte(`"open exchange api token"`),
+		[]byte(`"file:`+filepath.Join(dir, "openexchange")+`", "csv_rates": {"path": "file:/not/a/secret"}`),
+		1,
+	)
+	if err := os.WriteFile(confPath, confContent, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RICHORBROKE_TENOR_API_TOKEN_FILE", filepath.Join(dir, "tenor"))
+	conf, err := Load(confPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.OpenExchangeApiToken != "openexchange secret" || conf.TenorApiToken != "tenor secret" {
+		t.Fatalf("secrets were not read from files: %+v", conf)
+	}
+	if conf.CsvRates.Path != "file:/not/a/secret" {
+		t.Fatalf("non-secret parameter was read from file: %s", conf.CsvRates.Path)
+	}
+
+	t.Setenv("RICHORBROKE_TENOR_API_TOKEN_FILE", filepath.Join(dir, "missing"))
+	if _, err := Load(confPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected error for missing secret file, got %v", err)
+	}
+}
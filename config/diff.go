@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Diff describes the parameters that differ in old and new configs,
+// one line per parameter. Values of secrets are not shown.
+func Diff(old, new *ServiceConfig) []string {
+	return diffFields(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), "")
+}
+
+func diffFields(old, new reflect.Value, prefix string) []string {
+	var changes []string
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		info := t.Field(i)
+		name := paramName(info)
+		if name == "" {
+			continue
+		}
+		oldField, newField := old.Field(i), new.Field(i)
+		if oldField.Kind() == reflect.Struct {
+			changes = append(changes, diffFields(oldField, newField, prefix+name+".")...)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		if info.Tag.Get("secret") == "true" {
+			changes = append(changes, fmt.Sprintf("%s%s changed", prefix, name))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s%s: %v -> %v", prefix, name, oldField.Interface(), newField.Interface()))
+	}
+	return changes
+}
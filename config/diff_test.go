@@ -0,0 +1,25 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := validConfig()
+	new := validConfig()
+	if changes := Diff(old, new); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+	new.TenorApiToken = "new token"
+	new.Verdict.RichSearchQuery = "money"
+	new.RatesProviders = []string{"openexchange", "ecb"}
+	expected := []string{
+		"tenor_api_token changed",
+		"rates_providers: [openexchange] -> [openexchange ecb]",
+		"verdict.rich_search_query: rich -> money",
+	}
+	if changes := Diff(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %q, got %q", expected, changes)
+	}
+}
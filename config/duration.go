@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var errIncorrectDuration = errors.New("incorrect duration, should be a string like \"1m30s\" or a number of seconds")
+
+// Duration is a time.Duration written in config either as
+// a string like "1m30s" or as a number of seconds.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case float64:
+		*d = Duration(value * float64(time.Second))
+		return nil
+	case string:
+		return d.parse(value)
+	}
+	return errIncorrectDuration
+}
+
+func (d *Duration) parse(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return errIncorrectDuration
+	}
+	*d = Duration(parsed)
+	return nil
+}
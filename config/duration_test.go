@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	testCases := [...]struct {
+		json     string
+		expected Duration
+		err      error
+	}{
+		{`"1m30s"`, Duration(90 * time.Second), nil},
+		{`90`, Duration(90 * time.Second), nil},
+		{`0.5`, Duration(500 * time.Millisecond), nil},
+		{`"90"`, 0, errIncorrectDuration},
+		{`true`, 0, errIncorrectDuration},
+	}
+	for _, tc := range testCases {
+		var d Duration
+		err := json.Unmarshal([]byte(tc.json), &d)
+		if !errors.Is(err, tc.err) || d != tc.expected {
+			t.Fatalf("%s: expected %s with error %v, got %s with error %v", tc.json, tc.expected, tc.err, d, err)
+		}
+	}
+}
+
+func TestDurationFromEnv(t *testing.T) {
+	t.Setenv("RICHORBROKE_SERVER_WRITE_TIMEOUT", "1m")
+	conf, err := Load("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Server.WriteTimeout != Duration(time.Minute) {
+		t.Fatalf("expected write timeout 1m, got %s", conf.Server.WriteTimeout)
+	}
+	// omitted timeouts are set to defaults
+	if conf.Server.ShutdownTimeout != Duration(30*time.Second) {
+		t.Fatalf("expected default shutdown timeout 30s, got %s", conf.Server.ShutdownTimeout)
+	}
+
+	t.Setenv("RICHORBROKE_SERVER_WRITE_TIMEOUT", "forever")
+	if _, err := Load("config.json"); !errors.Is(err, errIncorrectEnvValue) {
+		t.Fatalf("expected %v, got %v", errIncorrectEnvValue, err)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables overriding config
+// parameters. The name of the variable is the prefix followed by the
+// upper-cased json path of the parameter joined with underscores,
+// e.g. RICHORBROKE_TENOR_API_TOKEN or RICHORBROKE_VERDICT_DIRECTION.
+const EnvPrefix = "RICHORBROKE_"
+
+// secretFilePrefix marks the value of a secret parameter as a path
+// to the file containing the secret, e.g. "file:/run/secrets/tenor".
+const secretFilePrefix = "file:"
+
+var errIncorrectEnvValue = errors.New("incorrect value of environment variable")
+
+// paramName returns the name of the parameter in config file,
+// or empty string if the field is not a parameter.
+func paramName(info reflect.StructField) string {
+	name := strings.Split(info.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// walkFields calls fn for every non-struct field of v, which must be
+// a struct, with the name of the environment variable for the field.
+func walkFields(v reflect.Value, prefix string, fn func(field reflect.Value, info reflect.StructField, envName string) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		info := t.Field(i)
+		name := paramName(info)
+		if name == "" {
+			continue
+		}
+		envName := prefix + strings.ToUpper(name)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := walkFields(field, envName+"_", fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, info, envName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overrideFromEnv sets the parameters for which environment variables
+// exist. Lists are comma-separated. Secret parameters can also be
+// given as a path to the file in the variable with _FILE suffix.
+func overrideFromEnv(conf *ServiceConfig, lookupEnv func(string) (string, bool)) error {
+	return walkFields(reflect.ValueOf(conf).Elem(), EnvPrefix, func(field reflect.Value, info reflect.StructField, envName string) error {
+		if info.Tag.Get("secret") == "true" {
+			if path, ok := lookupEnv(envName + "_FILE"); ok {
+				field.SetString(secretFilePrefix + path)
+			}
+		}
+		value, ok := lookupEnv(envName)
+		if !ok {
+			return nil
+		}
+		if err := setFromString(field, value); err != nil {
+			return fmt.Errorf("%w %s: %v", errIncorrectEnvValue, envName, err)
+		}
+		return nil
+	})
+}
+
+func setFromString(field reflect.Value, value string) error {
+	if d, ok := field.Addr().Interface().(*Duration); ok {
+		return d.parse(value)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported parameter type %s", field.Type())
+	}
+	return nil
+}
+
+// resolveSecrets replaces the secret parameters referencing
+// files with the contents of these files.
+func resolveSecrets(conf *ServiceConfig) error {
+	return walkFields(reflect.ValueOf(conf).Elem(), EnvPrefix, func(field reflect.Value, info reflect.StructField, _ string) error {
+		if info.Tag.Get("secret") != "true" || !strings.HasPrefix(field.String(), secretFilePrefix) {
+			return nil
+		}
+		secret, err := os.ReadFile(strings.TrimPrefix(field.String(), secretFilePrefix))
+		if err != nil {
+			return err
+		}
+		field.SetString(strings.TrimRight(string(secret), "\r\n"))
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var errUnsupportedConfigFormat = errors.New("unsupported config file format, should be json, yaml or toml")
+
+// readFile unmarshals the config file into conf. Yaml and toml
+// files use the same keys as json, so they are converted to json
+// first and there's only one set of tags on the config structs.
+func readFile(path string, conf *ServiceConfig) error {
+	confContent, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var parsed map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return json.Unmarshal(confContent, conf)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(confContent, &parsed)
+	case ".toml":
+		err = toml.Unmarshal(confContent, &parsed)
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedConfigFormat, path)
+	}
+	if err != nil {
+		return err
+	}
+	confContent, err = json.Marshal(parsed)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(confContent, conf)
+}
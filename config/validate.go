@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Ghytro/ab_interview/common"
+)
+
+var errIncorrectLimit = errors.New("incorrect value of the limit")
+var errIncorrectStableThreshold = errors.New("incorrect value of the stable threshold")
+var errIncorrectThresholdMode = errors.New("incorrect stable threshold mode, should be either \"absolute\" or \"percent\"")
+var errIncorrectDirection = errors.New("incorrect verdict direction, should be either \"strength\" or \"quote\"")
+var errNegativeTimeout = errors.New("timeout can't be negative")
+var errNonPositiveInterval = errors.New("interval should be positive")
+var errNegativeTTL = errors.New("ttl should not be negative, zero means until evicted")
+var errRequestTimeoutTooLong = errors.New("request timeout should be less than write timeout, otherwise timeout response can't be written")
+var errIncorrectPort = errors.New("incorrect port, should be in range 1-65535")
+var errMissingParameter = errors.New("parameter is required")
+var errIncorrectUrl = errors.New("incorrect url, should be absolute http(s) url")
+var errNoTrailingSlash = errors.New("url should end with a slash")
+var errUnknownCurrency = errors.New("unknown currency code")
+var errUnknownCacheType = errors.New("unknown cache type, should be one of \"redis\", \"memory\", \"none\"")
+var errIncorrectCacheSize = errors.New("incorrect cache size, should be positive")
+var errIncorrectBlobSize = errors.New("incorrect blob size, should be positive and not exceed cache.media.max_bytes")
+var errIncorrectRedisAddr = errors.New("incorrect redis address, should be host:port")
+var errIncorrectRedisDB = errors.New("incorrect redis db number")
+var errUnknownRatesProvider = errors.New("unknown rates provider, should be one of \"openexchange\", \"ecb\", \"cbr\", \"csv\"")
+var errUnknownGifProvider = errors.New("unknown gif provider, should be one of \"tenor\", \"giphy\", \"local\"")
+
+// ValidationError is a problem with one of the config parameters.
+type ValidationError struct {
+	Param string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Param + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors are all the problems found in config at once.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("config has %d problem(s):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// Is reports whether any of the problems is target.
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) check(ok bool, param string, err error) {
+	if !ok {
+		v.errs = append(v.errs, &ValidationError{param, err})
+	}
+}
+
+func (v *validator) required(value string, param string) {
+	v.check(value != "", param, errMissingParameter)
+}
+
+// baseUrl checks the url other paths are appended to.
+func (v *validator) baseUrl(value string, param string) {
+	if value == "" {
+		v.check(false, param, errMissingParameter)
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.check(false, param, errIncorrectUrl)
+		return
+	}
+	v.check(strings.HasSuffix(value, "/"), param, errNoTrailingSlash)
+}
+
+func (v *validator) currency(value string, param string) {
+	if value == "" {
+		v.check(false, param, errMissingParameter)
+		return
+	}
+	v.check(common.CurrencyExists(value), param, fmt.Errorf("%w %s", errUnknownCurrency, value))
+}
+
+func (v *validator) redisAddr(value string, param string) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || host == "" {
+		v.check(false, param, errIncorrectRedisAddr)
+		return
+	}
+	portNumber, err := strconv.Atoi(port)
+	v.check(err == nil && portNumber > 0 && portNumber <= 65535, param, errIncorrectRedisAddr)
+}
+
+// Validate checks the config and reports all the problems found at once
+// as ValidationErrors. Parameters of the providers which are not used
+// are not checked. Defaults should be set before validation.
+func (c *ServiceConfig) Validate() error {
+	v := new(validator)
+	v.check(c.Port > 0 && c.Port <= 65535, "port", errIncorrectPort)
+	v.currency(c.BaseCurrencyId, "base_currency_id")
+	switch c.Cache.Type {
+	case "redis":
+		v.redisAddr(c.RedisClientOptions.Addr, "redis_client_options.addr")
+		v.check(c.RedisClientOptions.DB >= 0, "redis_client_options.db", errIncorrectRedisDB)
+		if !c.Cache.L1.Disabled {
+			v.check(c.Cache.L1.MaxBytes > 0, "cache.l1.max_bytes", errIncorrectCacheSize)
+			v.check(c.Cache.L1.TTL > 0, "cache.l1.ttl", errNonPositiveInterval)
+		}
+		v.check(c.Cache.Media.MaxBytes > 0, "cache.media.max_bytes", errIncorrectCacheSize)
+		v.check(
+			c.Cache.Media.MaxBlobSize > 0 && c.Cache.Media.MaxBlobSize <= c.Cache.Media.MaxBytes,
+			"cache.media.max_blob_size",
+			errIncorrectBlobSize,
+		)
+		if c.Cache.Lock.Enabled {
+			v.check(c.Cache.Lock.TTL > 0, "cache.lock.ttl", errNonPositiveInterval)
+		}
+	case "memory":
+		v.check(c.Cache.MemorySize > 0, "cache.memory_size", errIncorrectCacheSize)
+		v.check(c.Cache.MemoryMaxBytes > 0, "cache.memory_max_bytes", errIncorrectCacheSize)
+	case "none":
+	default:
+		v.check(false, "cache.type", fmt.Errorf("%w: %s", errUnknownCacheType, c.Cache.Type))
+	}
+	v.check(c.Cache.TTL.CurrentRates > 0, "cache.ttl.current_rates", errNonPositiveInterval)
+	v.check(c.Cache.TTL.HistoricalRates >= 0, "cache.ttl.historical_rates", errNegativeTTL)
+	v.check(c.Cache.TTL.GifIds > 0, "cache.ttl.gif_ids", errNonPositiveInterval)
+	v.check(c.Cache.TTL.Gifs >= 0, "cache.ttl.gifs", errNegativeTTL)
+
+	v.check(c.RatesProviderCooldown > 0, "rates_provider_cooldown", errNonPositiveInterval)
+	for _, provider := range c.RatesProviders {
+		switch provider {
+		case "openexchange":
+			v.required(c.OpenExchangeApiToken, "openexchange_api_token")
+			v.baseUrl(c.OpenExchangeBaseUrl, "openexchange_base_url")
+		case "ecb":
+			v.baseUrl(c.Ecb.BaseUrl, "ecb.base_url")
+		case "cbr":
+			v.baseUrl(c.Cbr.BaseUrl, "cbr.base_url")
+		case "csv":
+			v.required(c.CsvRates.Path, "csv_rates.path")
+			v.currency(c.CsvRates.BaseCurrencyId, "csv_rates.base_currency_id")
+		default:
+			v.check(false, "rates_providers", fmt.Errorf("%w: %s", errUnknownRatesProvider, provider))
+		}
+	}
+
+	switch c.GifProvider {
+	case "tenor":
+		v.required(c.TenorApiToken, "tenor_api_token")
+		v.baseUrl(c.TenorBaseUrl, "tenor_base_url")
+		v.baseUrl(c.TenorMediaStorageBaseUrl, "tenor_media_storage_base_url")
+		v.check(c.TenorSearchQueryLimit >= 0, "tenor_search_query_limit", errIncorrectLimit)
+	case "giphy":
+		v.required(c.Giphy.ApiToken, "giphy.api_token")
+		v.baseUrl(c.Giphy.BaseUrl, "giphy.base_url")
+		v.baseUrl(c.Giphy.MediaBaseUrl, "giphy.media_base_url")
+		v.check(c.Giphy.SearchQueryLimit >= 0, "giphy.search_query_limit", errIncorrectLimit)
+	case "local":
+		v.required(c.LocalGifs.Dir, "local_gifs.dir")
+	default:
+		v.check(false, "gif_provider", fmt.Errorf("%w: %s", errUnknownGifProvider, c.GifProvider))
+	}
+
+	v.check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout", errNegativeTimeout)
+	v.check(c.Server.ReadTimeout >= 0, "server.read_timeout", errNegativeTimeout)
+	v.check(c.Server.WriteTimeout >= 0, "server.write_timeout", errNegativeTimeout)
+	v.check(c.Server.IdleTimeout >= 0, "server.idle_timeout", errNegativeTimeout)
+	v.check(c.Server.ShutdownTimeout >= 0, "server.shutdown_timeout", errNegativeTimeout)
+	v.check(c.Server.RequestTimeout >= 0, "server.request_timeout", errNegativeTimeout)
+	v.check(
+		c.Server.WriteTimeout == 0 || c.Server.RequestTimeout < c.Server.WriteTimeout,
+		"server.request_timeout",
+		errRequestTimeoutTooLong,
+	)
+	v.check(c.HttpClient.Timeout >= 0, "http_client.timeout", errNegativeTimeout)
+	v.check(c.Currencies.SyncInterval > 0, "currencies.sync_interval", errNonPositiveInterval)
+
+	v.check(c.Verdict.StableThreshold >= 0, "verdict.stable_threshold", errIncorrectStableThreshold)
+	v.check(IsValidDirection(c.Verdict.Direction), "verdict.direction", errIncorrectDirection)
+	switch c.Verdict.StableThresholdMode {
+	case ThresholdModeAbsolute, ThresholdModePercent:
+	default:
+		v.check(false, "verdict.stable_threshold_mode", errIncorrectThresholdMode)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func validConfig() *ServiceConfig {
+	conf := &ServiceConfig{
+		Port:                     8080,
+		OpenExchangeApiToken:     "token",
+		OpenExchangeBaseUrl:      "https://openexchangerates.org/api/",
+		TenorApiToken:            "token",
+		TenorBaseUrl:             "https://g.tenor.com/v1/",
+		TenorMediaStorageBaseUrl: "https://media.tenor.com/images/",
+		RedisClientOptions:       RedisClientConfig{Addr: "127.0.0.1:6379"},
+		BaseCurrencyId:           "USD",
+	}
+	conf.SetDefaults()
+	return conf
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	withoutRedis := validConfig()
+	withoutRedis.Cache.Type = "memory"
+	withoutRedis.RedisClientOptions.Addr = ""
+	if err := withoutRedis.Validate(); err != nil {
+		t.Fatalf("expected redis options not to be checked without redis cache, got %v", err)
+	}
+
+	testCases := [...]struct {
+		name   string
+		modify func(c *ServiceConfig)
+		param  string
+		err    error
+	}{
+		{"port", func(c *ServiceConfig) { c.Port = 70000 }, "port", errIncorrectPort},
+		{"missing token", func(c *ServiceConfig) { c.TenorApiToken = "" }, "tenor_api_token", errMissingParameter},
+		{"relative url", func(c *ServiceConfig) { c.OpenExchangeBaseUrl = "/api/" }, "openexchange_base_url", errIncorrectUrl},
+		{"no trailing slash", func(c *ServiceConfig) { c.TenorBaseUrl = "https://g.tenor.com/v1" }, "tenor_base_url", errNoTrailingSlash},
+		{"unknown currency", func(c *ServiceConfig) { c.BaseCurrencyId = "XXX" }, "base_currency_id", errUnknownCurrency},
+		{"redis addr", func(c *ServiceConfig) { c.RedisClientOptions.Addr = "127.0.0.1" }, "redis_client_options.addr", errIncorrectRedisAddr},
+		{"redis port", func(c *ServiceConfig) { c.RedisClientOptions.Addr = "redis:port" }, "redis_client_options.addr", errIncorrectRedisAddr},
+		{"cache type", func(c *ServiceConfig) { c.Cache.Type = "memcached" }, "cache.type", errUnknownCacheType},
+		{"cache size", func(c *ServiceConfig) { c.Cache.Type, c.Cache.MemorySize = "memory", -1 }, "cache.memory_size", errIncorrectCacheSize},
+		{"cache bytes", func(c *ServiceConfig) { c.Cache.Type, c.Cache.MemoryMaxBytes = "memory", -1 }, "cache.memory_max_bytes", errIncorrectCacheSize},
+		{"l1 cache size", func(c *ServiceConfig) { c.Cache.L1.MaxBytes = -1 }, "cache.l1.max_bytes", errIncorrectCacheSize},
+		{"l1 cache ttl", func(c *ServiceConfig) { c.Cache.L1.TTL = -1 }, "cache.l1.ttl", errNonPositiveInterval},
+		{"media cache size", func(c *ServiceConfig) { c.Cache.Media.MaxBytes = -1 }, "cache.media.max_bytes", errIncorrectCacheSize},
+		{"blob size", func(c *ServiceConfig) { c.Cache.Media.MaxBlobSize = c.Cache.Media.MaxBytes + 1 }, "cache.media.max_blob_size", errIncorrectBlobSize},
+		{"lock ttl", func(c *ServiceConfig) { c.Cache.Lock = LockConfig{true, -1} }, "cache.lock.ttl", errNonPositiveInterval},
+		{"current rates ttl", func(c *ServiceConfig) { c.Cache.TTL.CurrentRates = -1 }, "cache.ttl.current_rates", errNonPositiveInterval},
+		{"historical rates ttl", func(c *ServiceConfig) { c.Cache.TTL.HistoricalRates = -1 }, "cache.ttl.historical_rates", errNegativeTTL},
+		{"gifs ttl", func(c *ServiceConfig) { c.Cache.TTL.Gifs = -1 }, "cache.ttl.gifs", errNegativeTTL},
+		{"rates provider", func(c *ServiceConfig) { c.RatesProviders = []string{"bank"} }, "rates_providers", errUnknownRatesProvider},
+		{"rates provider cooldown", func(c *ServiceConfig) { c.RatesProviderCooldown = -1 }, "rates_provider_cooldown", errNonPositiveInterval},
+		{"gif provider", func(c *ServiceConfig) { c.GifProvider = "imgur" }, "gif_provider", errUnknownGifProvider},
+		{"csv provider", func(c *ServiceConfig) { c.RatesProviders = []string{"csv"} }, "csv_rates.path", errMissingParameter},
+		{"direction", func(c *ServiceConfig) { c.Verdict.Direction = "sideways" }, "verdict.direction", errIncorrectDirection},
+		{"sync interval", func(c *ServiceConfig) { c.Currencies.SyncInterval = -1 }, "currencies.sync_interval", errNonPositiveInterval},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := validConfig()
+			tc.modify(conf)
+			err := conf.Validate()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if errs := err.(ValidationErrors); errs[0].Param != tc.param {
+				t.Fatalf("expected problem with %s, got %v", tc.param, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	conf := validConfig()
+	conf.Port = 0
+	conf.BaseCurrencyId = ""
+	conf.TenorMediaStorageBaseUrl = "media.tenor.com"
+	err := conf.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("expected 3 problems, got %v", err)
+	}
+	for _, target := range [...]error{errIncorrectPort, errMissingParameter, errIncorrectUrl} {
+		if !errors.Is(err, target) {
+			t.Fatalf("expected %v to be reported, got %v", target, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// replacing the file produces a burst of events,
+// onChange is called once the burst is over
+const watchDebounce = 100 * time.Millisecond
+
+// Watch calls onChange each time the file at path is written or replaced.
+// The directory of the file is watched rather than the file itself,
+// because editors and orchestrators often replace the file with a new one
+// instead of writing into it. If path is a symlink, it is resolved again
+// after each burst of events, so the swap of a symlink on the way to the
+// file is noticed too, like the one of "..data" in kubernetes ConfigMap
+// volumes, and the directory of the resolved file is watched as well.
+// onChange is not called while the file is missing. Watching stops when
+// the returned function is called.
+func Watch(path string, onChange func()) (stop func() error, err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	w := &fileWatcher{watcher: watcher, path: path}
+	w.setTarget(resolvePath(path))
+	go w.run(onChange)
+	return watcher.Close, nil
+}
+
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	path    string
+	// the file the path resolves to, empty while it's missing
+	target string
+}
+
+// resolvePath returns the file the path points to
+// through symlinks or empty string if it's missing.
+func resolvePath(path string) string {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Clean(target)
+}
+
+// setTarget switches the watch from the directory of the
+// previous target to the directory of the new one.
+func (w *fileWatcher) setTarget(target string) {
+	dir, prevDir := filepath.Dir(target), filepath.Dir(w.target)
+	pathDir := filepath.Dir(w.path)
+	if w.target != "" && prevDir != pathDir && prevDir != dir {
+		// the watch is already gone if the directory was removed
+		w.watcher.Remove(prevDir)
+	}
+	if target != "" && dir != pathDir && dir != prevDir {
+		if err := w.watcher.Add(dir); err != nil {
+			log.Println("config.Watch:", err)
+		}
+	}
+	w.target = target
+}
+
+func (w *fileWatcher) run(onChange func()) {
+	var debounce <-chan time.Time
+	changed := false
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if name == w.path || name == w.target {
+				changed = true
+			}
+			// the events of other files may be the swap of a symlink on
+			// the way to the file, the path is resolved after the burst
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			debounce = nil
+			if target := resolvePath(w.path); target != w.target {
+				w.setTarget(target)
+				changed = true
+			}
+			if changed && w.target != "" {
+				onChange()
+			}
+			changed = false
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println("config.Watch:", err)
+		}
+	}
+}
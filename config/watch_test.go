@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 16)
+	stop, err := Watch(path, func() { changed <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+		t.Fatal("change of other file was reported")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// file replaced by rename, like editors and kubernetes do
+	tmpPath := filepath.Join(dir, "config.json.tmp")
+	if err := os.WriteFile(tmpPath, []byte(`{"port": 8081}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change of config file was not reported")
+	}
+}
+
+// expectChanges checks that onChange was called the given number of times.
+func expectChanges(t *testing.T, changed chan struct{}, expected int) {
+	t.Helper()
+	for i := 0; i < expected; i++ {
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d changes, but got %d", expected, i)
+		}
+	}
+	select {
+	case <-changed:
+		t.Fatalf("expected %d changes, but got more", expected)
+	case <-time.After(3 * watchDebounce):
+	}
+}
+
+func TestWatchSymlinkSwap(t *testing.T) {
+	// the layout of kubernetes ConfigMap volume: the file is a symlink
+	// to "..data/config.json", and "..data" is a symlink to the directory
+	// with the current version which is swapped by rename on update
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		if err := os.Mkdir(filepath.Join(dir, version), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "config.json"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("..v1", "{}")
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.json")
+	if err := os.Symlink(filepath.Join("..data", "config.json"), path); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 16)
+	stop, err := Watch(path, func() { changed <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	writeVersion("..v2", `{"port": 8081}`)
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "..v1")); err != nil {
+		t.Fatal(err)
+	}
+	// the whole swap is reported once
+	expectChanges(t, changed, 1)
+
+	// the resolved file is watched too
+	if err := os.WriteFile(filepath.Join(dir, "..v2", "config.json"), []byte(`{"port": 8082}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changed, 1)
+}
+
+func TestWatchRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 16)
+	stop, err := Watch(path, func() { changed <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// missing file is not reported, the recreated one is
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changed, 0)
+	if err := os.WriteFile(path, []byte(`{"port": 8081}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changed, 1)
+}
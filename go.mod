@@ -3,6 +3,17 @@ module github.com/Ghytro/ab_interview
 go 1.17
 
 require (
-	github.com/go-redis/redis v6.15.9+incompatible // indirect
-	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/BurntSushi/toml v1.2.0
+	github.com/alicebob/miniredis/v2 v2.23.0
+	github.com/fsnotify/fsnotify v1.6.0
+	github.com/go-redis/redis v6.15.9+incompatible
+	github.com/gorilla/mux v1.8.0
+	golang.org/x/sync v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/yuin/gopher-lua v0.0.0-20210529063254-f4c35e4016d9 // indirect
+	golang.org/x/sys v0.0.0-20220908164124-27713097b956 // indirect
 )
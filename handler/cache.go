@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Ghytro/ab_interview/cache"
+)
+
+type cacheTierStats struct {
+	Name   string `json:"name"`
+	Hits   int64  `json:"hits"`
+	Misses int64  `json:"misses"`
+	// known only for the in-memory tier
+	Values *int   `json:"values,omitempty"`
+	Bytes  *int64 `json:"bytes,omitempty"`
+}
+
+// mediaCacheUsage is how much of the gifs budget in redis is taken.
+type mediaCacheUsage struct {
+	Values   int64 `json:"values"`
+	Bytes    int64 `json:"bytes"`
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+type cacheStatsResponse struct {
+	Type  string           `json:"type"`
+	Tiers []cacheTierStats `json:"tiers"`
+	// null unless the cache is redis, or if redis is not available
+	Media *mediaCacheUsage `json:"media"`
+}
+
+// CacheStatsHandler responds with the hit and miss counters of the cache
+// tiers, the counters are kept while the cache survives config reloads,
+// and with the usage of the gifs budget in redis.
+func (s *Service) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	state := s.current()
+	resp := cacheStatsResponse{Type: state.config.Cache.Type, Tiers: []cacheTierStats{}}
+	if state.gifBudget != nil {
+		if usage, err := state.gifBudget.Usage(); err == nil {
+			resp.Media = &mediaCacheUsage{usage.Values, usage.Bytes, usage.MaxBytes}
+		}
+	}
+	for _, tier := range cache.Stats(state.cache, state.gifCache) {
+		stats := cacheTierStats{Name: tier.Name, Hits: tier.Hits, Misses: tier.Misses}
+		if tier.Name == "memory" {
+			values, bytes := tier.Values, tier.Bytes
+			stats.Values, stats.Bytes = &values, &bytes
+		}
+		resp.Tiers = append(resp.Tiers, stats)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/rates"
+)
+
+type currencyInfo struct {
+	Code        string `json:"code"`
+	NumericCode int    `json:"numeric_code,omitempty"`
+	Name        string `json:"name"`
+	// null if the currency has no minor units
+	MinorUnits *int                `json:"minor_units"`
+	Symbol     string              `json:"symbol,omitempty"`
+	Countries  []string            `json:"countries,omitempty"`
+	Kind       common.CurrencyKind `json:"kind,omitempty"`
+	Historical bool                `json:"historical,omitempty"`
+}
+
+func newCurrencyInfo(c common.Currency) currencyInfo {
+	info := currencyInfo{
+		Code:        c.Code,
+		NumericCode: c.NumericCode,
+		Name:        c.Name,
+		Symbol:      c.Symbol,
+		Countries:   c.Countries,
+		Kind:        c.Kind,
+		Historical:  c.Historical,
+	}
+	if c.MinorUnits != common.NoMinorUnits {
+		minorUnits := c.MinorUnits
+		info.MinorUnits = &minorUnits
+	}
+	return info
+}
+
+type currenciesResponse struct {
+	Currencies []currencyInfo `json:"currencies"`
+}
+
+// CurrenciesHandler responds with all the currencies
+// in catalog and their metadata sorted by code.
+func (s *Service) CurrenciesHandler(w http.ResponseWriter, r *http.Request) {
+	currencies := s.currencies.All()
+	resp := currenciesResponse{Currencies: make([]currencyInfo, len(currencies))}
+	for i, c := range currencies {
+		resp.Currencies[i] = newCurrencyInfo(c)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// syncCurrencies replaces the catalog with the currencies listed by the
+// rates providers. On failure the catalog is kept as is, so until the
+// first successful sync the builtin currencies are used.
+func (s *Service) syncCurrencies(ctx context.Context) error {
+	state := s.current()
+	currencies, err := state.rates.Currencies(ctx, time.Duration(state.config.Currencies.SyncInterval))
+	if err != nil {
+		return err
+	}
+	s.currencies.Replace(currencies)
+	state.logger.LogIfVerbose("currency catalog synced with the rates providers")
+	return nil
+}
+
+// SyncCurrencies syncs the catalog right away and then every
+// currencies.sync_interval from config, until ctx is done.
+func (s *Service) SyncCurrencies(ctx context.Context) {
+	for {
+		err := s.syncCurrencies(ctx)
+		switch {
+		case errors.Is(err, rates.ErrNoCurrenciesProviders):
+			// nothing to sync with, the builtin currencies are used
+		case err != nil && ctx.Err() == nil:
+			log.Println("currency catalog was not synced:", err)
+		}
+		timer := time.NewTimer(time.Duration(s.current().config.Currencies.SyncInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -1,78 +1,182 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"log"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Ghytro/ab_interview/common"
-	"github.com/Ghytro/ab_interview/openexchange"
-	"github.com/Ghytro/ab_interview/tenor"
+	"github.com/Ghytro/ab_interview/config"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/sync/errgroup"
 )
 
 var errIncorrectCurrencyCode = errors.New("incorrect currency code")
+var errConflictingBase = errors.New("base currency in path and in query parameters differ")
+var errIncorrectDirection = errors.New("incorrect direction, should be either \"strength\" or \"quote\"")
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-func DiffHandler(w http.ResponseWriter, r *http.Request) {
-	common.LogIfVerbose("incoming request to " + r.URL.Path)
-	today := time.Now()
-	yesterday := today.Add(-24 * time.Hour)
-	chanYesterdayCourse := make(chan float64)
-	chanTodayCourse := make(chan float64)
-	chanError := make(chan error)
-	currency := mux.Vars(r)["currency_id"]
-	getHistoricalRates := func(t time.Time, c chan float64) {
-		m, err := openexchange.HistoricalRates(t)
-		if err != nil {
-			log.Println(err)
-			chanError <- err
-			return
-		}
-		val, ok := m[currency]
-		if !ok {
-			log.Println(errIncorrectCurrencyCode)
-			chanError <- errIncorrectCurrencyCode
-			return
-		}
-		chanError <- nil
-		c <- val
+type course struct {
+	Value    float64
+	Provider string
+}
+
+func ratesProvidersHeader(fromProvider, toProvider string) string {
+	if fromProvider == toProvider {
+		return fromProvider
 	}
-	go getHistoricalRates(today, chanTodayCourse)
-	go getHistoricalRates(yesterday, chanYesterdayCourse)
-	for i := 0; i < 2; i++ {
-		err := <-chanError
-		if err != nil {
-			if err == errIncorrectCurrencyCode {
-				w.WriteHeader(http.StatusNotFound)
-			} else if err == openexchange.ErrIncorrectOpenExchangeToken {
-				w.WriteHeader(http.StatusUnauthorized)
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
+	return fromProvider + ", " + toProvider
+}
+
+// unknownCurrencyError is returned for the codes missing
+// from the currency catalog, it suggests the similar known codes.
+type unknownCurrencyError struct {
+	code        string
+	suggestions []string
+}
+
+func (e *unknownCurrencyError) Error() string {
+	msg := errIncorrectCurrencyCode.Error() + " " + e.code
+	if len(e.suggestions) > 0 {
+		msg += ", did you mean " + strings.Join(e.suggestions, ", ") + "?"
+	}
+	return msg
+}
+
+func (e *unknownCurrencyError) Unwrap() error {
+	return errIncorrectCurrencyCode
+}
+
+// parseCurrency normalizes the currency code from request and looks it
+// up in the catalog, so unknown codes are rejected without asking the
+// cache and the rates providers. The currency is returned as it was in
+// the catalog, which may be replaced by sync while the request is served.
+func (s *Service) parseCurrency(code string) (common.Currency, error) {
+	code = common.NormalizeCurrencyCode(code)
+	currency, ok := s.currencies.Lookup(code)
+	if !ok {
+		return common.Currency{}, &unknownCurrencyError{code, s.currencies.Similar(code)}
+	}
+	return currency, nil
+}
+
+// requestedBase returns the base currency either from the currency pair
+// in path (/api/diff/{base_id}/{currency_id}), or from the base query
+// parameter, or the default one from config.
+func (s *Service) requestedBase(r *http.Request, defaultBase string) (string, error) {
+	pathBase := common.NormalizeCurrencyCode(mux.Vars(r)["base_id"])
+	queryBase := common.NormalizeCurrencyCode(r.URL.Query().Get("base"))
+	switch {
+	case pathBase != "" && queryBase != "" && pathBase != queryBase:
+		return "", errConflictingBase
+	case pathBase != "":
+		return s.parseCurrencyCode(pathBase)
+	case queryBase != "":
+		return s.parseCurrencyCode(queryBase)
+	}
+	return defaultBase, nil
+}
+
+func (s *Service) parseCurrencyCode(code string) (string, error) {
+	currency, err := s.parseCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	return currency.Code, nil
+}
+
+func (s *Service) DiffHandler(w http.ResponseWriter, r *http.Request) {
+	state := s.current()
+	state.logger.LogIfVerbose("incoming request to " + r.URL.Path)
+	window, err := parseComparisonWindow(r.URL.Query(), time.Now())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	base, err := s.requestedBase(r, state.config.BaseCurrencyId)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	currency, err := s.parseCurrency(mux.Vars(r)["currency_id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	direction := r.URL.Query().Get("direction")
+	if !config.IsValidDirection(direction) {
+		writeError(w, errIncorrectDirection)
+		return
+	}
+	if direction == "" {
+		direction = state.config.Verdict.Direction
+	}
+	// upstream calls are aborted when the client disconnects or
+	// the request takes too long
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(state.config.Server.RequestTimeout))
+	defer cancel()
+	// the first error cancels the other request,
+	// both are finished by the time Wait returns
+	group, groupCtx := errgroup.WithContext(ctx)
+	var from, to course
+	getHistoricalRates := func(t time.Time, c *course) func() error {
+		return func() error {
+			table, err := state.rates.HistoricalRates(groupCtx, t, base)
+			if err != nil {
+				log.Println(err)
+				return err
 			}
-			return
+			val, ok := table.Rates[currency.Code]
+			if !ok {
+				log.Println(errIncorrectCurrencyCode)
+				return errIncorrectCurrencyCode
+			}
+			*c = course{val, table.Provider}
+			return nil
 		}
 	}
-	todayCourse, yesterdayCourse := <-chanTodayCourse, <-chanYesterdayCourse
+	group.Go(getHistoricalRates(window.To, &to))
+	group.Go(getHistoricalRates(window.From, &from))
+	if err := group.Wait(); err != nil {
+		writeError(w, err)
+		return
+	}
+	toCourse, fromCourse := to.Value, from.Value
+	w.Header().Set("X-Rates-Provider", ratesProvidersHeader(from.Provider, to.Provider))
 
-	var (
-		gif *tenor.Gif
-		err error
-	)
-	if todayCourse > yesterdayCourse {
-		gif, err = tenor.GetRandomGif("rich")
-	} else {
-		gif, err = tenor.GetRandomGif("broke")
+	v, err := decideVerdict(fromCourse, toCourse, direction, &state.config.Verdict)
+	if err != nil {
+		log.Println(err)
+		writeError(w, err)
+		return
+	}
+	searchQuery := verdictSearchQuery(v, &state.config.Verdict)
+	if acceptsJSON(r.Header.Get("Accept")) {
+		gif, err := state.gifs.GetRandomGifInfo(ctx, searchQuery)
+		if err != nil {
+			log.Println(err)
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(
+			newVerdictResponse(currency, base, window, fromCourse, toCourse, direction, v, gif),
+		)
+		return
 	}
+	gif, err := state.gifs.GetRandomGif(ctx, searchQuery)
 	if err != nil {
 		log.Println(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "image/gif")
@@ -0,0 +1,606 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/internal/fakes"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+// EUR became more expensive relative to USD: one dollar buys less euros
+var testRates = map[string]map[string]float64{
+	"2024-01-01": {"USD": 1, "EUR": 0.9, "JPY": 140},
+	"2024-02-01": {"USD": 1, "EUR": 0.8, "JPY": 0},
+}
+
+var testGifs = map[string][]string{
+	"rich":  {"rich-gif"},
+	"broke": {"broke-gif"},
+	"meh":   {"meh-gif"},
+}
+
+type testEnv struct {
+	openExchange *fakes.OpenExchange
+	tenor        *fakes.Tenor
+	conf         *config.ServiceConfig
+	service      *Service
+	router       http.Handler
+}
+
+// newTestEnv creates the service talking to fake upstream apis.
+// If redisAddr is empty, the service works without cache.
+func newTestEnv(t *testing.T, redisAddr string, openExchangeToken string) *testEnv {
+	openExchange := fakes.NewOpenExchange("token", testRates)
+	t.Cleanup(openExchange.Close)
+	tenor := fakes.NewTenor("token", testGifs)
+	t.Cleanup(tenor.Close)
+
+	if redisAddr == "" {
+		// nothing listens there
+		redisAddr = "127.0.0.1:1"
+	}
+	conf := &config.ServiceConfig{
+		OpenExchangeBaseUrl:      openExchange.BaseUrl(),
+		OpenExchangeApiToken:     openExchangeToken,
+		TenorBaseUrl:             tenor.BaseUrl(),
+		TenorMediaStorageBaseUrl: tenor.MediaStorageBaseUrl(),
+		TenorApiToken:            "token",
+		BaseCurrencyId:           "USD",
+		Verdict: config.VerdictConfig{
+			Direction:           config.DirectionStrength,
+			StableThreshold:     0.1,
+			StableThresholdMode: config.ThresholdModePercent,
+		},
+	}
+	// without redis the in-memory cache in front of it would cache anyway
+	conf.Cache.L1.Disabled = redisAddr == "127.0.0.1:1"
+	conf.SetDefaults()
+	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { redisClient.Close() })
+	service, err := NewService(conf, Dependencies{RedisClient: redisClient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return &testEnv{openExchange, tenor, conf, service, service.Router()}
+}
+
+func (env *testEnv) get(url string, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	env.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// upstreamRequests returns the total number of requests to the fake apis.
+func (env *testEnv) upstreamRequests() int {
+	searchRequests, mediaRequests := env.tenor.Requests()
+	return env.openExchange.Requests() + searchRequests + mediaRequests
+}
+
+func TestDiffHandlerDirection(t *testing.T) {
+	env := newTestEnv(t, "", "token")
+	testCases := [...]struct {
+		query           string
+		expectedVerdict verdict
+		expectedGifId   string
+	}{
+		{"from=2024-01-01&to=2024-02-01", verdictRich, "rich-gif"},
+		{"from=2024-01-01&to=2024-02-01&direction=strength", verdictRich, "rich-gif"},
+		{"from=2024-01-01&to=2024-02-01&direction=quote", verdictBroke, "broke-gif"},
+	}
+	for _, tc := range testCases {
+		rec := env.get("/api/diff/EUR?"+tc.query, "application/json")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("query %q: expected status %d, but got %d", tc.query, http.StatusOK, rec.Code)
+		}
+		resp := new(verdictResponse)
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Verdict != tc.expectedVerdict || resp.Gif.Id != tc.expectedGifId {
+			t.Fatalf(
+				"query %q: expected verdict %s with gif %s, but got %s with gif %s",
+				tc.query,
+				tc.expectedVerdict,
+				tc.expectedGifId,
+				resp.Verdict,
+				resp.Gif.Id,
+			)
+		}
+	}
+
+	rec := env.get("/api/diff/EUR?direction=sideways", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for incorrect direction, but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestDiffHandlerCache(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	env := newTestEnv(t, mr.Addr(), "token")
+
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	rec := env.get(url, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cache miss: expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), fakes.GifContent("rich-gif")) {
+		t.Fatalf("cache miss: unexpected gif content %q", rec.Body.String())
+	}
+	for _, key := range [...]string{
+		"rates_cache:2024-01-01:USD",
+		"rates_cache:2024-02-01:USD",
+		"gif_cache:tenor:gif_ids:rich",
+		"gif_cache:tenor:gif:rich-gif",
+	} {
+		if !mr.Exists(key) {
+			t.Fatalf("cache miss: key %s was not cached", key)
+		}
+	}
+	// rates of the past days don't change
+	if ttl := mr.TTL("rates_cache:2024-01-01:USD"); ttl != 0 {
+		t.Fatalf("expected historical rates to be kept until evicted, got ttl %v", ttl)
+	}
+	if ttl := mr.TTL("gif_cache:tenor:gif_ids:rich"); ttl != time.Duration(env.conf.Cache.TTL.GifIds) {
+		t.Fatalf("expected gif ids to be cached for %v, got %v", env.conf.Cache.TTL.GifIds, ttl)
+	}
+
+	requestsBefore := env.upstreamRequests()
+	rec = env.get(url, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cache hit: expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), fakes.GifContent("rich-gif")) {
+		t.Fatalf("cache hit: unexpected gif content %q", rec.Body.String())
+	}
+	if requestsAfter := env.upstreamRequests(); requestsAfter != requestsBefore {
+		t.Fatalf("cache hit: expected no upstream requests, but got %d", requestsAfter-requestsBefore)
+	}
+}
+
+func TestDiffHandlerCacheTypes(t *testing.T) {
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	testCases := [...]struct {
+		cache    config.CacheConfig
+		upstream bool
+	}{
+		{config.CacheConfig{Type: "memory", MemorySize: 100, MemoryMaxBytes: 1 << 20}, false},
+		{config.CacheConfig{Type: "none"}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cache.Type, func(t *testing.T) {
+			env := newTestEnv(t, "", "token")
+			conf := *env.conf
+			conf.Cache = tc.cache
+			if err := env.service.Reload(&conf); err != nil {
+				t.Fatal(err)
+			}
+			if rec := env.get(url, ""); rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+			}
+			requestsBefore := env.upstreamRequests()
+			if rec := env.get(url, ""); rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+			}
+			if upstream := env.upstreamRequests() != requestsBefore; upstream != tc.upstream {
+				t.Fatalf("expected upstream requests on the second request: %t, got %t", tc.upstream, upstream)
+			}
+		})
+	}
+}
+
+func TestDiffHandlerRedisDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := newTestEnv(t, mr.Addr(), "token")
+	mr.Close()
+
+	for i := 0; i < 2; i++ {
+		rec := env.get("/api/diff/EUR?from=2024-01-01&to=2024-02-01", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), fakes.GifContent("rich-gif")) {
+			t.Fatalf("unexpected gif content %q", rec.Body.String())
+		}
+	}
+	// values fetched while redis is down are kept in memory
+	if env.openExchange.Requests() != 2 {
+		t.Fatalf("expected only the first request to go upstream, but got %d rates requests", env.openExchange.Requests())
+	}
+}
+
+func TestCacheStatsHandler(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	env := newTestEnv(t, mr.Addr(), "token")
+	stats := func() *cacheStatsResponse {
+		rec := env.get("/api/cache/stats", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+		}
+		resp := new(cacheStatsResponse)
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	for i := 0; i < 2; i++ {
+		if rec := env.get(url, ""); rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+		}
+	}
+	expectedMedia := mediaCacheUsage{1, int64(len(fakes.GifContent("rich-gif"))), int64(env.conf.Cache.Media.MaxBytes)}
+	if media := stats().Media; media == nil || *media != expectedMedia {
+		t.Fatalf("expected media usage %+v, got %+v", expectedMedia, media)
+	}
+
+	// the hot values are served from memory, not from redis
+	mr.FlushAll()
+	if rec := env.get(url, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	resp := stats()
+	if resp.Type != "redis" || len(resp.Tiers) != 2 {
+		t.Fatalf("expected memory and redis tiers, got %+v", resp)
+	}
+	memory, shared := resp.Tiers[0], resp.Tiers[1]
+	// rates of both dates and the gif, on the second and third requests
+	if memory.Hits != 6 || memory.Values == nil || *memory.Values == 0 || *memory.Bytes == 0 {
+		t.Fatalf("unexpected memory tier stats %+v", memory)
+	}
+	// gif ids are always taken from redis, as a random one is picked
+	if shared.Hits != 1 || shared.Values != nil {
+		t.Fatalf("unexpected redis tier stats %+v", shared)
+	}
+}
+
+// expectProblem checks that the response is a problem with the given status and code.
+func expectProblem(t *testing.T, rec *httptest.ResponseRecorder, expectedStatus int, expectedCode string) *problem {
+	t.Helper()
+	if rec.Code != expectedStatus {
+		t.Fatalf("expected status %d, but got %d", expectedStatus, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Fatalf("expected content type %s, but got %s", problemContentType, contentType)
+	}
+	p := new(problem)
+	if err := json.NewDecoder(rec.Body).Decode(p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != expectedStatus || p.Code != expectedCode || p.Title == "" {
+		t.Fatalf("expected problem with status %d and code %s, but got %+v", expectedStatus, expectedCode, p)
+	}
+	return p
+}
+
+func TestDiffHandlerErrors(t *testing.T) {
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	testCases := [...]struct {
+		name           string
+		modify         func(conf *config.ServiceConfig)
+		url            string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			"incorrect rates token",
+			func(conf *config.ServiceConfig) { conf.OpenExchangeApiToken = "wrong-token" },
+			url,
+			http.StatusUnauthorized,
+			"rates_provider_unauthorized",
+		},
+		{
+			"incorrect gif token",
+			func(conf *config.ServiceConfig) { conf.TenorApiToken = "wrong-token" },
+			url,
+			http.StatusUnauthorized,
+			"gif_provider_unauthorized",
+		},
+		{
+			"no gifs",
+			func(conf *config.ServiceConfig) { conf.Verdict.RichSearchQuery = "nothing" },
+			url,
+			http.StatusBadGateway,
+			"no_gifs_found",
+		},
+		{"unknown currency", nil, "/api/diff/XXX?from=2024-01-01&to=2024-02-01", http.StatusNotFound, "unknown_currency"},
+		{"currency without rates", nil, "/api/diff/GBP?from=2024-01-01&to=2024-02-01", http.StatusNotFound, "unknown_currency"},
+		{"no rates for date", nil, "/api/diff/EUR?from=2023-01-01&to=2024-02-01", http.StatusNotFound, "no_rates_for_date"},
+		{"zero rate", nil, "/api/diff/JPY?from=2024-01-01&to=2024-02-01", http.StatusBadGateway, "invalid_upstream_rate"},
+		{"incorrect date", nil, "/api/diff/EUR?from=yesterday", http.StatusBadRequest, "incorrect_request"},
+		{"incorrect direction", nil, url + "&direction=sideways", http.StatusBadRequest, "incorrect_request"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv(t, "", "token")
+			if tc.modify != nil {
+				conf := *env.conf
+				tc.modify(&conf)
+				if err := env.service.Reload(&conf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expectProblem(t, env.get(tc.url, ""), tc.expectedStatus, tc.expectedCode)
+		})
+	}
+}
+
+func TestDiffHandlerCurrencyCodes(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	env := newTestEnv(t, mr.Addr(), "token")
+
+	// codes are case-insensitive
+	for _, url := range [...]string{
+		"/api/diff/eur?from=2024-01-01&to=2024-02-01",
+		"/api/diff/usd/Eur?from=2024-01-01&to=2024-02-01",
+		"/api/diff/EUR?from=2024-01-01&to=2024-02-01&base=usd",
+		"/api/diff/usd/EUR?from=2024-01-01&to=2024-02-01&base=USD",
+	} {
+		rec := env.get(url, "application/json")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, but got %d", url, http.StatusOK, rec.Code)
+		}
+		resp := new(verdictResponse)
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Currency != "EUR" || resp.Base != "USD" {
+			t.Fatalf("%s: expected normalized codes, but got %s/%s", url, resp.Base, resp.Currency)
+		}
+		if resp.FromAmount != "€0.90" || resp.ToAmount != "€0.80" || resp.AbsoluteChangeAmount != "-€0.10" {
+			t.Fatalf("%s: amounts were formatted incorrectly: %+v", url, resp)
+		}
+	}
+
+	// unknown codes are rejected without asking cache and upstream apis
+	mr.FlushAll()
+	requestsBefore := env.upstreamRequests()
+	testCases := [...]struct {
+		url        string
+		suggestion string
+	}{
+		{"/api/diff/EUT?from=2024-01-01&to=2024-02-01", "EUR"},
+		{"/api/diff/eru?from=2024-01-01&to=2024-02-01", "EUR"},
+		{"/api/diff/USB/EUR?from=2024-01-01&to=2024-02-01", "USD"},
+		{"/api/diff/EUR?from=2024-01-01&to=2024-02-01&base=US", "USD"},
+	}
+	for _, tc := range testCases {
+		p := expectProblem(t, env.get(tc.url, ""), http.StatusNotFound, "unknown_currency")
+		if !containsString(p.Suggestions, tc.suggestion) || !strings.Contains(p.Detail, "did you mean") {
+			t.Fatalf("%s: expected %s to be suggested, but got %+v", tc.url, tc.suggestion, p)
+		}
+	}
+	p := expectProblem(t, env.get("/api/diff/QQQ?from=2024-01-01&to=2024-02-01", ""), http.StatusNotFound, "unknown_currency")
+	if len(p.Suggestions) != 0 {
+		t.Fatalf("expected no suggestions, but got %v", p.Suggestions)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("expected cache not to be used, but got keys %v", keys)
+	}
+	if requestsAfter := env.upstreamRequests(); requestsAfter != requestsBefore {
+		t.Fatalf("expected no upstream requests, but got %d", requestsAfter-requestsBefore)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiffHandlerTimeouts(t *testing.T) {
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	testCases := [...]struct {
+		name   string
+		modify func(env *testEnv, conf *config.ServiceConfig)
+	}{
+		{"hanging rates api", func(env *testEnv, conf *config.ServiceConfig) {
+			conf.Server.RequestTimeout = config.Duration(50 * time.Millisecond)
+			env.openExchange.SetDelay(time.Second)
+		}},
+		{"hanging gif api", func(env *testEnv, conf *config.ServiceConfig) {
+			conf.Server.RequestTimeout = config.Duration(50 * time.Millisecond)
+			env.tenor.SetDelay(time.Second)
+		}},
+		{"http client timeout", func(env *testEnv, conf *config.ServiceConfig) {
+			conf.HttpClient.Timeout = config.Duration(50 * time.Millisecond)
+			env.openExchange.SetDelay(time.Second)
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv(t, "", "token")
+			conf := *env.conf
+			tc.modify(env, &conf)
+			if err := env.service.Reload(&conf); err != nil {
+				t.Fatal(err)
+			}
+			expectProblem(t, env.get(url, ""), http.StatusGatewayTimeout, "upstream_timeout")
+		})
+	}
+}
+
+func TestDiffHandlerClientDisconnect(t *testing.T) {
+	env := newTestEnv(t, "", "token")
+	env.openExchange.SetDelay(5 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/diff/EUR?from=2024-01-01&to=2024-02-01", nil).WithContext(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	env.router.ServeHTTP(httptest.NewRecorder(), req)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected upstream calls to be aborted on disconnect, but the request took %s", elapsed)
+	}
+}
+
+func TestDiffHandlerCoalescing(t *testing.T) {
+	env := newTestEnv(t, "", "token")
+	// the requests come while the first fetches are in flight
+	env.openExchange.SetDelay(100 * time.Millisecond)
+	env.tenor.SetDelay(100 * time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := env.get("/api/diff/EUR?from=2024-01-01&to=2024-02-01", ""); rec.Code != http.StatusOK {
+				t.Errorf("expected status %d, but got %d", http.StatusOK, rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	searchRequests, mediaRequests := env.tenor.Requests()
+	if env.openExchange.Requests() != 2 || searchRequests != 1 || mediaRequests != 1 {
+		t.Fatalf(
+			"expected one upstream request per date, search query and gif, got %d, %d, %d",
+			env.openExchange.Requests(),
+			searchRequests,
+			mediaRequests,
+		)
+	}
+}
+
+func TestDiffHandlerNoGoroutineLeak(t *testing.T) {
+	env := newTestEnv(t, "", "token")
+	failingUrls := [...]string{
+		// no rates for one of the dates
+		"/api/diff/EUR?from=2023-01-01&to=2024-02-01",
+		"/api/diff/EUR?from=2024-01-01&to=2023-02-01",
+		"/api/diff/XXX?from=2024-01-01&to=2024-02-01",
+	}
+	request := func() {
+		for _, url := range failingUrls {
+			if rec := env.get(url, ""); rec.Code == http.StatusOK {
+				t.Fatalf("%s: expected request to fail", url)
+			}
+		}
+	}
+	// connections to the fake apis are established during warm up
+	request()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		request()
+	}
+	// goroutines of the finished requests may need some time to exit
+	deadline := time.Now().Add(time.Second)
+	after := runtime.NumGoroutine()
+	for after > before+5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before+5 {
+		t.Fatalf("goroutines leaked: %d before requests, %d after", before, after)
+	}
+}
+
+func (env *testEnv) currencies(t *testing.T) []currencyInfo {
+	t.Helper()
+	rec := env.get("/api/currencies", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+	}
+	resp := new(currenciesResponse)
+	if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Currencies
+}
+
+func TestCurrenciesHandler(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	env := newTestEnv(t, mr.Addr(), "token")
+
+	// the listing is not available, builtin currencies are kept
+	if err := env.service.syncCurrencies(context.Background()); err == nil {
+		t.Fatal("expected sync to fail without currencies listing")
+	}
+	builtin := env.currencies(t)
+	if len(builtin) < 100 || !sort.SliceIsSorted(builtin, func(i, j int) bool { return builtin[i].Code < builtin[j].Code }) {
+		t.Fatalf("expected sorted builtin currencies, but got %v", builtin)
+	}
+
+	env.openExchange.Currencies = map[string]string{"EUR": "Euro", "USD": "United States Dollar", "XYZ": "Test Coin"}
+	if err := env.service.syncCurrencies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the metadata of builtin currencies is kept
+	eur, _ := common.LookupCurrency("EUR")
+	eurMinorUnits := 2
+	expected := []currencyInfo{
+		{
+			Code:        "EUR",
+			NumericCode: 978,
+			Name:        "Euro",
+			MinorUnits:  &eurMinorUnits,
+			Symbol:      "€",
+			Countries:   eur.Countries,
+			Kind:        common.CurrencyFiat,
+		},
+		{Code: "XYZ", Name: "Test Coin"},
+	}
+	synced := env.currencies(t)
+	if len(synced) != 3 || !reflect.DeepEqual(synced[0], expected[0]) || !reflect.DeepEqual(synced[2], expected[1]) {
+		t.Fatalf("expected synced currencies %+v, but got %+v", expected, synced)
+	}
+	if !mr.Exists("currencies_cache") {
+		t.Fatal("synced currencies were not cached")
+	}
+	// currencies missing from the synced catalog are rejected right away
+	requestsBefore := env.upstreamRequests()
+	expectProblem(t, env.get("/api/diff/GBP?from=2024-01-01&to=2024-02-01", ""), http.StatusNotFound, "unknown_currency")
+	if requestsAfter := env.upstreamRequests(); requestsAfter != requestsBefore {
+		t.Fatalf("expected no upstream requests, but got %d", requestsAfter-requestsBefore)
+	}
+
+	// the catalog is taken from cache by another instance
+	other := newTestEnv(t, mr.Addr(), "token")
+	if err := other.service.syncCurrencies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if fromCache := other.currencies(t); !reflect.DeepEqual(fromCache, synced) {
+		t.Fatalf("expected currencies from cache %+v, but got %+v", synced, fromCache)
+	}
+	if other.openExchange.Requests() != 0 {
+		t.Fatal("expected currencies to be taken from cache")
+	}
+}
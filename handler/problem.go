@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/Ghytro/ab_interview/media"
+	"github.com/Ghytro/ab_interview/openexchange"
+	"github.com/Ghytro/ab_interview/rates"
+	"github.com/Ghytro/ab_interview/tenor"
+)
+
+const problemContentType = "application/problem+json"
+
+// problem is an error response in the format of RFC 7807
+// extended with the stable code clients can rely on.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+	// known currency codes similar to the unknown one
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// errorKind describes how the errors of one kind are reported.
+// Codes and titles are a part of api and should not be changed.
+type errorKind struct {
+	status int
+	code   string
+	title  string
+}
+
+var (
+	kindIncorrectRequest = errorKind{
+		http.StatusBadRequest, "incorrect_request", "Incorrect request parameters",
+	}
+	kindUnknownCurrency = errorKind{
+		http.StatusNotFound, "unknown_currency", "Unknown currency",
+	}
+	kindUnsupportedBase = errorKind{
+		http.StatusNotFound, "unsupported_base_currency", "Rates relative to the base currency are not available",
+	}
+	kindNoRatesForDate = errorKind{
+		http.StatusNotFound, "no_rates_for_date", "No rates for the requested date",
+	}
+	kindRatesProviderUnauthorized = errorKind{
+		http.StatusUnauthorized, "rates_provider_unauthorized", "Rates provider rejected the access token",
+	}
+	kindRatesProviderQuotaExceeded = errorKind{
+		http.StatusServiceUnavailable, "rates_provider_quota_exceeded", "Rates provider requests quota exceeded",
+	}
+	kindGifProviderUnauthorized = errorKind{
+		http.StatusUnauthorized, "gif_provider_unauthorized", "Gif provider rejected the access token",
+	}
+	kindNoGifsFound = errorKind{
+		http.StatusBadGateway, "no_gifs_found", "No gifs found for the verdict",
+	}
+	kindInvalidUpstreamRate = errorKind{
+		http.StatusBadGateway, "invalid_upstream_rate", "Rates provider returned an invalid rate",
+	}
+	kindUpstreamTimeout = errorKind{
+		http.StatusGatewayTimeout, "upstream_timeout", "External api did not respond in time",
+	}
+	kindInternal = errorKind{
+		http.StatusInternalServerError, "internal_error", "Internal error",
+	}
+)
+
+// errorKinds maps the known errors to their kinds,
+// the errors not listed here are internal ones.
+var errorKinds = []struct {
+	err  error
+	kind errorKind
+}{
+	{errIncorrectDateFormat, kindIncorrectRequest},
+	{errIncorrectPeriod, kindIncorrectRequest},
+	{errFutureDate, kindIncorrectRequest},
+	{errEmptyWindow, kindIncorrectRequest},
+	{errPeriodWithFromDate, kindIncorrectRequest},
+	{errConflictingBase, kindIncorrectRequest},
+	{errIncorrectDirection, kindIncorrectRequest},
+	{errIncorrectCurrencyCode, kindUnknownCurrency},
+	{rates.ErrIncorrectBaseCurrency, kindUnsupportedBase},
+	{openexchange.ErrIncorrectBaseCurrency, kindUnsupportedBase},
+	{rates.ErrNoRatesForDate, kindNoRatesForDate},
+	{openexchange.ErrIncorrectDate, kindNoRatesForDate},
+	{openexchange.ErrIncorrectOpenExchangeToken, kindRatesProviderUnauthorized},
+	{openexchange.ErrOpenExchangeQuotaExceeded, kindRatesProviderQuotaExceeded},
+	{tenor.ErrIncorrectTenorToken, kindGifProviderUnauthorized},
+	{media.ErrIncorrectGiphyToken, kindGifProviderUnauthorized},
+	{media.ErrNoGifsFound, kindNoGifsFound},
+	{errInvalidRate, kindInvalidUpstreamRate},
+}
+
+// isTimeout reports whether the error is caused by the request
+// deadline or by the timeout of http client.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func errorKindOf(err error) errorKind {
+	if isTimeout(err) {
+		return kindUpstreamTimeout
+	}
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return kindInternal
+}
+
+// writeError responds with the problem describing the error.
+// Messages of internal errors are not shown to clients.
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) {
+		// the client has gone, nobody will read the response
+		return
+	}
+	kind := errorKindOf(err)
+	p := problem{
+		Type:   "urn:rich-or-broke:error:" + kind.code,
+		Title:  kind.title,
+		Status: kind.status,
+		Code:   kind.code,
+	}
+	if kind != kindInternal {
+		p.Detail = err.Error()
+	}
+	var unknownCurrency *unknownCurrencyError
+	if errors.As(err, &unknownCurrency) {
+		p.Suggestions = unknownCurrency.suggestions
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(kind.status)
+	json.NewEncoder(w).Encode(p)
+}
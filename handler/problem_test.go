@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Ghytro/ab_interview/openexchange"
+	"github.com/Ghytro/ab_interview/tenor"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	testCases := [...]struct {
+		err  error
+		kind errorKind
+	}{
+		{errIncorrectPeriod, kindIncorrectRequest},
+		{openexchange.ErrIncorrectDate, kindNoRatesForDate},
+		{openexchange.ErrIncorrectBaseCurrency, kindUnsupportedBase},
+		{openexchange.ErrOpenExchangeQuotaExceeded, kindRatesProviderQuotaExceeded},
+		{tenor.ErrIncorrectTenorToken, kindGifProviderUnauthorized},
+		{fmt.Errorf("search failed: %w", tenor.ErrIncorrectTenorToken), kindGifProviderUnauthorized},
+		{fmt.Errorf("get failed: %w", context.DeadlineExceeded), kindUpstreamTimeout},
+		{errors.New("something went wrong"), kindInternal},
+	}
+	for _, tc := range testCases {
+		if kind := errorKindOf(tc.err); kind != tc.kind {
+			t.Fatalf("%v: expected %s, got %s", tc.err, tc.kind.code, kind.code)
+		}
+	}
+}
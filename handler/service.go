@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/media"
+	"github.com/Ghytro/ab_interview/rates"
+	"github.com/go-redis/redis"
+	"github.com/gorilla/mux"
+)
+
+// replaced redis client is closed with a delay,
+// so the requests in progress could finish using it
+const replacedRedisClientCloseDelay = time.Second * 30
+
+// Dependencies of the service. Omitted ones are created from config.
+type Dependencies struct {
+	HttpClient *http.Client
+	// replaces the cache selected in config, gifs are kept there too
+	Cache          cache.Cache
+	RedisClient    *redis.Client
+	RatesProviders []rates.RatesProvider
+	GifProvider    media.GifProvider
+}
+
+// Service is an instance of rich-or-broke, all of its state
+// is kept inside, so several services can live in one process.
+type Service struct {
+	deps Dependencies
+	// survives reloads, synced with the rates providers by SyncCurrencies
+	currencies *common.CurrencyCatalog
+	// *serviceState, replaced as a whole on reload, so every
+	// request works with the consistent config it started with
+	state atomic.Value
+	// serializes reloads
+	m sync.Mutex
+}
+
+// serviceState is everything in the service that depends on config.
+type serviceState struct {
+	config *config.ServiceConfig
+	logger *common.Logger
+	// shared by all upstream apis
+	httpClient *http.Client
+	// shared by all the stores
+	cache cache.Cache
+	// the same as cache unless it is redis, where gifs are kept within the budget
+	gifCache cache.Cache
+	// nil unless the cache is redis, kept separately from the
+	// caches in front of it to keep its health on reload
+	redis       *cache.Redis
+	gifBudget   *cache.Bounded
+	redisClient *redis.Client
+	// redis client is closed with the service only if the service created it
+	ownsRedisClient bool
+	rates           *rates.Store
+	gifs            *media.Store
+}
+
+// NewService creates the service from config, the omitted optional
+// parameters take the defaults, so the config may be built in code.
+func NewService(conf *config.ServiceConfig, deps Dependencies) (*Service, error) {
+	s := &Service{deps: deps, currencies: common.NewCurrencyCatalog()}
+	state, err := s.newState(conf, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.state.Store(state)
+	return s, nil
+}
+
+func (s *Service) current() *serviceState {
+	return s.state.Load().(*serviceState)
+}
+
+// newState builds the state from config with the defaults filled in, the
+// given config is not modified. Http client and cache of the previous
+// state are reused if their options didn't change, the providers are
+// created anew, so their health is reset.
+func (s *Service) newState(conf *config.ServiceConfig, prev *serviceState) (*serviceState, error) {
+	withDefaults := *conf
+	withDefaults.SetDefaults()
+	conf = &withDefaults
+	state := &serviceState{
+		config: conf,
+		logger: &common.Logger{Verbose: conf.IsVerbose},
+	}
+	switch {
+	case s.deps.HttpClient != nil:
+		state.httpClient = s.deps.HttpClient
+	case prev != nil && prev.config.HttpClient == conf.HttpClient:
+		state.httpClient = prev.httpClient
+	default:
+		state.httpClient = &http.Client{Timeout: time.Duration(conf.HttpClient.Timeout)}
+	}
+	state.newCache(s.deps, prev)
+
+	ratesProviders := s.deps.RatesProviders
+	if ratesProviders == nil {
+		var err error
+		ratesProviders, err = rates.NewProvidersFromConfig(conf, state.httpClient)
+		if err != nil {
+			state.closeRedisClientUnlessShared(prev)
+			return nil, err
+		}
+	}
+	gifProvider := s.deps.GifProvider
+	if gifProvider == nil {
+		var err error
+		gifProvider, err = media.NewProviderFromConfig(conf, state.httpClient)
+		if err != nil {
+			state.closeRedisClientUnlessShared(prev)
+			return nil, err
+		}
+	}
+	// upstream fetches are coalesced within the instance anyway
+	var lock *cache.Lock
+	if state.redis != nil && conf.Cache.Lock.Enabled {
+		lock = cache.NewLock(state.redis, time.Duration(conf.Cache.Lock.TTL))
+	}
+	ttl := conf.Cache.TTL
+	state.rates = rates.NewStore(
+		state.cache,
+		lock,
+		state.logger,
+		rates.CacheTTL{CurrentDay: time.Duration(ttl.CurrentRates), PastDays: time.Duration(ttl.HistoricalRates)},
+		conf.BaseCurrencyId,
+		time.Duration(conf.RatesProviderCooldown),
+		ratesProviders,
+	)
+	state.gifs = media.NewStore(
+		state.cache,
+		state.gifCache,
+		lock,
+		state.logger,
+		media.CacheTTL{GifIds: time.Duration(ttl.GifIds), Gifs: time.Duration(ttl.Gifs)},
+		gifProvider,
+	)
+	return state, nil
+}
+
+// newCache creates the cache selected in config, unless it is given in
+// deps. The cache of the previous state is reused if it has the same
+// options, so the cached values and the health of redis are kept.
+func (st *serviceState) newCache(deps Dependencies, prev *serviceState) {
+	if deps.Cache != nil {
+		st.cache = deps.Cache
+		st.gifCache = deps.Cache
+		return
+	}
+	conf, redisClient := st.config, deps.RedisClient
+	if prev != nil && prev.config.Cache.Type == conf.Cache.Type {
+		switch {
+		case conf.Cache.Type == "memory" &&
+			prev.config.Cache.MemorySize == conf.Cache.MemorySize &&
+			prev.config.Cache.MemoryMaxBytes == conf.Cache.MemoryMaxBytes,
+			conf.Cache.Type == "none":
+			st.cache = prev.cache
+			st.gifCache = prev.gifCache
+			return
+		case conf.Cache.Type == "redis" &&
+			(redisClient != nil || prev.config.RedisClientOptions == conf.RedisClientOptions):
+			st.redis = prev.redis
+			st.redisClient = prev.redisClient
+			st.ownsRedisClient = prev.ownsRedisClient
+			if prev.config.Cache.L1 == conf.Cache.L1 && prev.config.Cache.Media == conf.Cache.Media {
+				st.cache = prev.cache
+				st.gifCache = prev.gifCache
+				st.gifBudget = prev.gifBudget
+			} else {
+				st.newRedisTiers()
+			}
+			return
+		}
+	}
+	switch conf.Cache.Type {
+	case "memory":
+		st.cache = cache.NewMemory(conf.Cache.MemorySize, int64(conf.Cache.MemoryMaxBytes))
+		st.gifCache = st.cache
+	case "none":
+		st.cache = cache.Noop{}
+		st.gifCache = st.cache
+	default:
+		st.redisClient = redisClient
+		if st.redisClient == nil {
+			st.redisClient = redis.NewClient(&redis.Options{
+				DB:          conf.RedisClientOptions.DB,
+				Password:    conf.RedisClientOptions.Password,
+				Addr:        conf.RedisClientOptions.Addr,
+				ReadTimeout: time.Millisecond * 100,
+			})
+			st.ownsRedisClient = true
+		}
+		st.redis = cache.NewRedis(st.redisClient)
+		st.newRedisTiers()
+	}
+}
+
+// newRedisTiers creates the caches on top of redis: the budget of gifs
+// and the in-memory cache in front of both, unless it is disabled.
+func (st *serviceState) newRedisTiers() {
+	conf := st.config.Cache
+	st.gifBudget = cache.NewBounded(
+		st.redis,
+		"gif_cache:budget",
+		int64(conf.Media.MaxBytes),
+		int64(conf.Media.MaxBlobSize),
+	)
+	if conf.L1.Disabled {
+		st.cache = st.redis
+		st.gifCache = st.gifBudget
+		return
+	}
+	l1 := cache.NewMemory(0, int64(conf.L1.MaxBytes))
+	st.cache = cache.NewTiered(l1, st.redis, time.Duration(conf.L1.TTL))
+	st.gifCache = cache.NewTiered(l1, st.gifBudget, time.Duration(conf.L1.TTL))
+}
+
+// closeRedisClientUnlessShared closes the redis client created for
+// the state, if the state is discarded.
+func (st *serviceState) closeRedisClientUnlessShared(prev *serviceState) {
+	if st.ownsRedisClient && (prev == nil || prev.redisClient != st.redisClient) {
+		st.redisClient.Close()
+	}
+}
+
+// Reload replaces the config of the running service. Requests in progress
+// finish with the old config, the new ones use the new config. The changed
+// parameters are logged. The port can't be changed without restart.
+func (s *Service) Reload(conf *config.ServiceConfig) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	prev := s.current()
+	state, err := s.newState(conf, prev)
+	if err != nil {
+		return err
+	}
+	s.state.Store(state)
+	changes := config.Diff(prev.config, state.config)
+	if len(changes) == 0 {
+		log.Println("config reloaded, nothing changed")
+	}
+	for _, change := range changes {
+		log.Println("config reloaded:", change)
+	}
+	if prev.config.Port != state.config.Port {
+		log.Println("port change takes effect only after restart")
+	}
+	if prev.ownsRedisClient && prev.redisClient != state.redisClient {
+		time.AfterFunc(replacedRedisClientCloseDelay, func() { prev.redisClient.Close() })
+	}
+	return nil
+}
+
+// Router returns the handler serving all the endpoints of the service.
+func (s *Service) Router() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/diff/{currency_id}", s.DiffHandler).Methods("GET")
+	router.HandleFunc("/api/diff/{base_id}/{currency_id}", s.DiffHandler).Methods("GET")
+	router.HandleFunc("/api/currencies", s.CurrenciesHandler).Methods("GET")
+	router.HandleFunc("/api/cache/stats", s.CacheStatsHandler).Methods("GET")
+	return router
+}
+
+// Close releases the resources held by the service.
+func (s *Service) Close() error {
+	state := s.current()
+	if !state.ownsRedisClient {
+		return nil
+	}
+	return state.redisClient.Close()
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/config"
+)
+
+func TestReload(t *testing.T) {
+	env := newTestEnv(t, "", "token")
+	const url = "/api/diff/EUR?from=2024-01-01&to=2024-02-01"
+	gifId := func() string {
+		rec := env.get(url, "application/json")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, but got %d", http.StatusOK, rec.Code)
+		}
+		resp := new(verdictResponse)
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Gif.Id
+	}
+	if id := gifId(); id != "rich-gif" {
+		t.Fatalf("expected rich-gif before reload, got %s", id)
+	}
+
+	newConf := *env.conf
+	newConf.Verdict.RichSearchQuery = "meh"
+	if err := env.service.Reload(&newConf); err != nil {
+		t.Fatal(err)
+	}
+	if id := gifId(); id != "meh-gif" {
+		t.Fatalf("expected meh-gif after reload, got %s", id)
+	}
+
+	newConf.OpenExchangeApiToken = "wrong-token"
+	if err := env.service.Reload(&newConf); err != nil {
+		t.Fatal(err)
+	}
+	if rec := env.get(url, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected new token to be used, but got status %d", rec.Code)
+	}
+}
+
+func TestReloadRedisClient(t *testing.T) {
+	conf := &config.ServiceConfig{
+		RedisClientOptions: config.RedisClientConfig{Addr: "127.0.0.1:1"},
+		BaseCurrencyId:     "USD",
+	}
+	service, err := NewService(conf, Dependencies{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer service.Close()
+	redisClient := service.current().redisClient
+
+	sameRedisConf := *conf
+	sameRedisConf.IsVerbose = true
+	if err := service.Reload(&sameRedisConf); err != nil {
+		t.Fatal(err)
+	}
+	if service.current().redisClient != redisClient {
+		t.Fatal("redis client was recreated with the same options")
+	}
+
+	otherRedisConf := sameRedisConf
+	otherRedisConf.RedisClientOptions.Addr = "127.0.0.1:2"
+	if err := service.Reload(&otherRedisConf); err != nil {
+		t.Fatal(err)
+	}
+	if service.current().redisClient == redisClient {
+		t.Fatal("redis client was not recreated with new options")
+	}
+}
+
+func TestServiceCacheDependency(t *testing.T) {
+	conf := &config.ServiceConfig{
+		RedisClientOptions: config.RedisClientConfig{Addr: "127.0.0.1:1"},
+		BaseCurrencyId:     "USD",
+	}
+	c := cache.NewMemory(100, 1<<20)
+	service, err := NewService(conf, Dependencies{Cache: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer service.Close()
+	if state := service.current(); state.cache != c || state.gifCache != c || state.redisClient != nil {
+		t.Fatal("cache from dependencies was not used")
+	}
+
+	// the given cache is kept whatever cache is selected in config
+	otherConf := *conf
+	otherConf.Cache.Type = "none"
+	if err := service.Reload(&otherConf); err != nil {
+		t.Fatal(err)
+	}
+	if state := service.current(); state.cache != c || state.gifCache != c {
+		t.Fatal("cache from dependencies was replaced on reload")
+	}
+}
+
+func TestNewServiceDefaults(t *testing.T) {
+	conf := &config.ServiceConfig{
+		RedisClientOptions: config.RedisClientConfig{Addr: "127.0.0.1:1"},
+		BaseCurrencyId:     "USD",
+	}
+	service, err := NewService(conf, Dependencies{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer service.Close()
+	state := service.current()
+	if state.config.Server.RequestTimeout <= 0 || state.config.Verdict.RichSearchQuery == "" {
+		t.Fatalf("expected defaults to be filled, got %+v", state.config)
+	}
+	if conf.Server.RequestTimeout != 0 {
+		t.Fatal("given config was modified")
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"strings"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/media"
+)
+
+var errInvalidRate = errors.New("rates provider returned a non-positive rate")
+
+type verdict string
+
+const (
+	verdictRich   verdict = "rich"
+	verdictBroke  verdict = "broke"
+	verdictStable verdict = "stable"
+)
+
+// comparedValue returns the value which growth means the "rich" verdict:
+// either the price of the currency in base currency units,
+// or the rate itself.
+func comparedValue(course float64, direction string) float64 {
+	if direction == config.DirectionStrength {
+		return 1 / course
+	}
+	return course
+}
+
+// decideVerdict fails if one of the rates is not positive,
+// the change can't be computed for such rates.
+func decideVerdict(fromCourse, toCourse float64, direction string, conf *config.VerdictConfig) (verdict, error) {
+	if fromCourse <= 0 || toCourse <= 0 {
+		return "", fmt.Errorf("%w: %f, %f", errInvalidRate, fromCourse, toCourse)
+	}
+	fromValue, toValue := comparedValue(fromCourse, direction), comparedValue(toCourse, direction)
+	change := toValue - fromValue
+	if conf.StableThresholdMode == config.ThresholdModePercent {
+		change = change / fromValue * 100
+	}
+	switch {
+	case math.Abs(change) <= conf.StableThreshold:
+		return verdictStable, nil
+	case change > 0:
+		return verdictRich, nil
+	}
+	return verdictBroke, nil
+}
+
+func verdictSearchQuery(v verdict, conf *config.VerdictConfig) string {
+	switch v {
+	case verdictRich:
+		return conf.RichSearchQuery
+	case verdictStable:
+		return conf.StableSearchQuery
+	}
+	return conf.BrokeSearchQuery
+}
+
+type gifInfo struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+type verdictResponse struct {
+	Currency         string  `json:"currency"`
+	CurrencyName     string  `json:"currency_name,omitempty"`
+	CurrencySymbol   string  `json:"currency_symbol,omitempty"`
+	Base             string  `json:"base"`
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	FromRate         float64 `json:"from_rate"`
+	ToRate           float64 `json:"to_rate"`
+	AbsoluteChange   float64 `json:"absolute_change"`
+	PercentageChange float64 `json:"percentage_change"`
+	// the rates and the change formatted as amounts of currency, e.g. "€0.91"
+	FromAmount           string  `json:"from_amount"`
+	ToAmount             string  `json:"to_amount"`
+	AbsoluteChangeAmount string  `json:"absolute_change_amount"`
+	Direction            string  `json:"direction"`
+	Verdict              verdict `json:"verdict"`
+	Gif                  gifInfo `json:"gif"`
+}
+
+func newVerdictResponse(
+	currency common.Currency,
+	base string,
+	window *comparisonWindow,
+	fromCourse, toCourse float64,
+	direction string,
+	v verdict,
+	gif *media.Gif,
+) *verdictResponse {
+	resp := &verdictResponse{
+		Currency:             currency.Code,
+		CurrencyName:         currency.Name,
+		CurrencySymbol:       currency.Symbol,
+		Base:                 base,
+		From:                 window.From.Format(dateLayout),
+		To:                   window.To.Format(dateLayout),
+		FromRate:             fromCourse,
+		ToRate:               toCourse,
+		AbsoluteChange:       toCourse - fromCourse,
+		FromAmount:           currency.FormatAmount(fromCourse),
+		ToAmount:             currency.FormatAmount(toCourse),
+		AbsoluteChangeAmount: currency.FormatAmount(toCourse - fromCourse),
+		Direction:            direction,
+		Verdict:              v,
+		Gif:                  gifInfo{gif.Id, gif.Url},
+	}
+	if fromCourse != 0 {
+		resp.PercentageChange = (toCourse - fromCourse) / fromCourse * 100
+	}
+	return resp
+}
+
+// acceptsJSON reports whether the client asked for the verdict
+// in JSON instead of the gif itself.
+func acceptsJSON(acceptHeader string) bool {
+	for _, mediaRange := range strings.Split(acceptHeader, ",") {
+		mediaType, _, err := mime.ParseMediaType(mediaRange)
+		if err == nil && mediaType == "application/json" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ghytro/ab_interview/config"
+)
+
+func TestAcceptsJSON(t *testing.T) {
+	testCases := [...]struct {
+		acceptHeader string
+		expected     bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"image/gif", false},
+		{"application/json", true},
+		{"image/gif;q=0.9, application/json", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"application/json; charset=utf-8", true},
+	}
+	for _, tc := range testCases {
+		if got := acceptsJSON(tc.acceptHeader); got != tc.expected {
+			t.Fatalf("accept header %q: expected %t, but got %t", tc.acceptHeader, tc.expected, got)
+		}
+	}
+}
+
+func TestDecideVerdict(t *testing.T) {
+	absoluteConf := &config.VerdictConfig{StableThreshold: 0.01, StableThresholdMode: config.ThresholdModeAbsolute}
+	percentConf := &config.VerdictConfig{StableThreshold: 1, StableThresholdMode: config.ThresholdModePercent}
+	zeroConf := &config.VerdictConfig{StableThresholdMode: config.ThresholdModeAbsolute}
+	testCases := [...]struct {
+		fromCourse float64
+		toCourse   float64
+		direction  string
+		conf       *config.VerdictConfig
+		expected   verdict
+	}{
+		{1, 1.5, config.DirectionQuote, absoluteConf, verdictRich},
+		{1.5, 1, config.DirectionQuote, absoluteConf, verdictBroke},
+		{1, 1.005, config.DirectionQuote, absoluteConf, verdictStable},
+		{1, 0.995, config.DirectionQuote, absoluteConf, verdictStable},
+		{100, 100.5, config.DirectionQuote, percentConf, verdictStable},
+		{100, 102, config.DirectionQuote, percentConf, verdictRich},
+		{100, 98, config.DirectionQuote, percentConf, verdictBroke},
+		{1, 1, config.DirectionQuote, zeroConf, verdictStable},
+		{1, 1.0001, config.DirectionQuote, zeroConf, verdictRich},
+		{1, 1.5, config.DirectionStrength, absoluteConf, verdictBroke},
+		{1.5, 1, config.DirectionStrength, absoluteConf, verdictRich},
+		{1, 1.005, config.DirectionStrength, absoluteConf, verdictStable},
+		{100, 102, config.DirectionStrength, percentConf, verdictBroke},
+		{100, 98, config.DirectionStrength, percentConf, verdictRich},
+		{100, 100.5, config.DirectionStrength, percentConf, verdictStable},
+		{1, 1, config.DirectionStrength, zeroConf, verdictStable},
+	}
+	for _, tc := range testCases {
+		got, err := decideVerdict(tc.fromCourse, tc.toCourse, tc.direction, tc.conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Fatalf(
+				"%s change from %f to %f with threshold %f %s: expected %s, but got %s",
+				tc.direction,
+				tc.fromCourse,
+				tc.toCourse,
+				tc.conf.StableThreshold,
+				tc.conf.StableThresholdMode,
+				tc.expected,
+				got,
+			)
+		}
+	}
+}
+
+func TestDecideVerdictInvalidRate(t *testing.T) {
+	conf := &config.VerdictConfig{StableThresholdMode: config.ThresholdModeAbsolute}
+	for _, courses := range [...][2]float64{{0, 1}, {1, 0}, {-1, 1}} {
+		if _, err := decideVerdict(courses[0], courses[1], config.DirectionQuote, conf); !errors.Is(err, errInvalidRate) {
+			t.Fatalf("change from %f to %f: expected error %v, but got %v", courses[0], courses[1], errInvalidRate, err)
+		}
+	}
+}
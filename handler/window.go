@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var errIncorrectDateFormat = errors.New("incorrect date format, expected YYYY-MM-DD")
+var errIncorrectPeriod = errors.New("incorrect period, expected a positive number followed by d, w, m or y (e.g. 7d)")
+var errFutureDate = errors.New("date should not be in the future")
+var errEmptyWindow = errors.New("from date should be earlier than to date")
+var errPeriodWithFromDate = errors.New("period can't be combined with from date")
+
+type comparisonWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, errIncorrectDateFormat
+	}
+	return t, nil
+}
+
+func parsePeriod(to time.Time, period string) (time.Time, error) {
+	if len(period) < 2 {
+		return time.Time{}, errIncorrectPeriod
+	}
+	n, err := strconv.Atoi(period[:len(period)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, errIncorrectPeriod
+	}
+	switch period[len(period)-1] {
+	case 'd':
+		return to.AddDate(0, 0, -n), nil
+	case 'w':
+		return to.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return to.AddDate(0, -n, 0), nil
+	case 'y':
+		return to.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, errIncorrectPeriod
+}
+
+// parseComparisonWindow reads from, to and period query parameters.
+// Without any of them the window is yesterday to today, as it used to be.
+func parseComparisonWindow(query url.Values, now time.Time) (*comparisonWindow, error) {
+	today, _ := time.Parse(dateLayout, now.UTC().Format(dateLayout))
+	window := &comparisonWindow{To: today}
+	if to := query.Get("to"); to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return nil, err
+		}
+		window.To = t
+	}
+
+	from, period := query.Get("from"), query.Get("period")
+	switch {
+	case from != "" && period != "":
+		return nil, errPeriodWithFromDate
+	case from != "":
+		t, err := parseDate(from)
+		if err != nil {
+			return nil, err
+		}
+		window.From = t
+	case period != "":
+		t, err := parsePeriod(window.To, period)
+		if err != nil {
+			return nil, err
+		}
+		window.From = t
+	default:
+		window.From = window.To.AddDate(0, 0, -1)
+	}
+
+	if window.To.After(today) || window.From.After(today) {
+		return nil, errFutureDate
+	}
+	if !window.From.Before(window.To) {
+		return nil, errEmptyWindow
+	}
+	return window, nil
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseComparisonWindow(t *testing.T) {
+	now := time.Date(2024, 3, 15, 13, 45, 0, 0, time.UTC)
+	testCases := [...]struct {
+		query        string
+		expectedFrom string
+		expectedTo   string
+		expectedErr  error
+	}{
+		{"", "2024-03-14", "2024-03-15", nil},
+		{"from=2024-01-01&to=2024-02-01", "2024-01-01", "2024-02-01", nil},
+		{"from=2024-01-01", "2024-01-01", "2024-03-15", nil},
+		{"period=7d", "2024-03-08", "2024-03-15", nil},
+		{"period=2w&to=2024-02-29", "2024-02-15", "2024-02-29", nil},
+		{"period=1m", "2024-02-15", "2024-03-15", nil},
+		{"period=1y", "2023-03-15", "2024-03-15", nil},
+		{"from=01.01.2024", "", "", errIncorrectDateFormat},
+		{"to=2024-13-01", "", "", errIncorrectDateFormat},
+		{"period=7", "", "", errIncorrectPeriod},
+		{"period=-7d", "", "", errIncorrectPeriod},
+		{"period=0d", "", "", errIncorrectPeriod},
+		{"period=7h", "", "", errIncorrectPeriod},
+		{"from=2024-01-01&period=7d", "", "", errPeriodWithFromDate},
+		{"to=2024-03-16", "", "", errFutureDate},
+		{"from=2024-04-01&to=2024-03-01", "", "", errFutureDate},
+		{"from=2024-03-01&to=2024-03-01", "", "", errEmptyWindow},
+		{"from=2024-03-02&to=2024-03-01", "", "", errEmptyWindow},
+	}
+	for _, tc := range testCases {
+		query, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		window, err := parseComparisonWindow(query, now)
+		if err != tc.expectedErr {
+			t.Fatalf("query %q: expected error %v, but got %v", tc.query, tc.expectedErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		from, to := window.From.Format(dateLayout), window.To.Format(dateLayout)
+		if from != tc.expectedFrom || to != tc.expectedTo {
+			t.Fatalf(
+				"query %q: expected window %s - %s, but got %s - %s",
+				tc.query,
+				tc.expectedFrom,
+				tc.expectedTo,
+				from,
+				to,
+			)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenExchange is a fake of openexchangerates.org historical api
+// serving the rates relative to USD only, like the free plan does,
+// and the currencies listing, if Currencies are set.
+type OpenExchange struct {
+	*httptest.Server
+	ApiToken    string
+	RatesByDate map[string]map[string]float64
+	Currencies  map[string]string
+
+	m        sync.Mutex
+	requests int
+	delay    time.Duration
+}
+
+func NewOpenExchange(apiToken string, ratesByDate map[string]map[string]float64) *OpenExchange {
+	f := &OpenExchange{ApiToken: apiToken, RatesByDate: ratesByDate}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// BaseUrl is the url to put into openexchange_base_url.
+func (f *OpenExchange) BaseUrl() string {
+	return f.URL + "/"
+}
+
+// Requests returns the number of requests served so far.
+func (f *OpenExchange) Requests() int {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.requests
+}
+
+func (f *OpenExchange) serve(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	f.requests++
+	delay := f.delay
+	f.m.Unlock()
+	if !wait(r, delay) {
+		return
+	}
+	if r.URL.Path == "/currencies.json" {
+		f.serveCurrencies(w)
+		return
+	}
+	f.serveHistorical(w, r)
+}
+
+func (f *OpenExchange) serveCurrencies(w http.ResponseWriter) {
+	if f.Currencies == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(f.Currencies)
+}
+
+func (f *OpenExchange) serveHistorical(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("app_id") != f.ApiToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if base := query.Get("base"); base != "" && base != "USD" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/historical/") || !strings.HasSuffix(r.URL.Path, ".json") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	date := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/historical/"), ".json")
+	rates, ok := f.RatesByDate[date]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"base":  "USD",
+		"rates": rates,
+	})
+}
+
+// SetDelay makes the fake respond after the delay,
+// unless the request is cancelled earlier.
+func (f *OpenExchange) SetDelay(delay time.Duration) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.delay = delay
+}
+
+// wait reports whether the delay passed before the request was cancelled.
+func wait(r *http.Request, delay time.Duration) bool {
+	if delay == 0 {
+		return true
+	}
+	select {
+	case <-time.After(delay):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
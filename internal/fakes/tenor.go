@@ -0,0 +1,115 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tenor is a fake of tenor v1 search api and its media storage.
+type Tenor struct {
+	*httptest.Server
+	ApiToken    string
+	GifsByQuery map[string][]string
+
+	m              sync.Mutex
+	searchRequests int
+	mediaRequests  int
+	delay          time.Duration
+}
+
+func NewTenor(apiToken string, gifsByQuery map[string][]string) *Tenor {
+	f := &Tenor{ApiToken: apiToken, GifsByQuery: gifsByQuery}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/search", f.serveSearch)
+	mux.HandleFunc("/images/", f.serveMedia)
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// BaseUrl is the url to put into tenor_base_url.
+func (f *Tenor) BaseUrl() string {
+	return f.URL + "/v1/"
+}
+
+// MediaStorageBaseUrl is the url to put into tenor_media_storage_base_url.
+func (f *Tenor) MediaStorageBaseUrl() string {
+	return f.URL + "/images/"
+}
+
+// GifContent returns the content served for the gif with the given id.
+func GifContent(gifId string) []byte {
+	return []byte("GIF89a" + gifId)
+}
+
+// Requests returns the number of search and media requests served so far.
+func (f *Tenor) Requests() (searchRequests, mediaRequests int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.searchRequests, f.mediaRequests
+}
+
+func (f *Tenor) serveSearch(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	f.searchRequests++
+	delay := f.delay
+	f.m.Unlock()
+	if !wait(r, delay) {
+		return
+	}
+
+	if r.URL.Query().Get("key") != f.ApiToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	type media struct {
+		Gif struct {
+			Url string `json:"url"`
+		} `json:"gif"`
+	}
+	type result struct {
+		Media []media `json:"media"`
+	}
+	resp := struct {
+		Results []result `json:"results"`
+	}{[]result{}}
+	for _, gifId := range f.GifsByQuery[r.URL.Query().Get("q")] {
+		var m media
+		m.Gif.Url = f.MediaStorageBaseUrl() + gifId + "/tenor.gif"
+		resp.Results = append(resp.Results, result{[]media{m}})
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *Tenor) serveMedia(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	f.mediaRequests++
+	delay := f.delay
+	f.m.Unlock()
+	if !wait(r, delay) {
+		return
+	}
+
+	gifId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/images/"), "/tenor.gif")
+	for _, gifIds := range f.GifsByQuery {
+		for _, id := range gifIds {
+			if id == gifId {
+				w.Header().Set("Content-Type", "image/gif")
+				w.Write(GifContent(gifId))
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// SetDelay makes the fake respond after the delay,
+// unless the request is cancelled earlier.
+func (f *Tenor) SetDelay(delay time.Duration) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.delay = delay
+}
@@ -1,17 +1,164 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Ghytro/ab_interview/config"
 	"github.com/Ghytro/ab_interview/handler"
-	"github.com/gorilla/mux"
+	"github.com/Ghytro/ab_interview/media"
+	"github.com/go-redis/redis"
 )
 
+func configPathFlag(flags *flag.FlagSet) *string {
+	return flags.String(
+		"config",
+		"config/config.json",
+		"path to json, yaml or toml config file, empty to read config from environment only",
+	)
+}
+
+// checkConfig loads the config and reports all of its problems,
+// returns the exit code of the check-config subcommand.
+func checkConfig(args []string) int {
+	flags := flag.NewFlagSet("check-config", flag.ExitOnError)
+	configPath := configPathFlag(flags)
+	flags.Parse(args)
+	if _, err := config.Load(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("config is valid")
+	return 0
+}
+
+// cleanupCache deletes the keys left in redis by the previous versions
+// of the service, returns the exit code of the cleanup-cache subcommand.
+func cleanupCache(args []string) int {
+	flags := flag.NewFlagSet("cleanup-cache", flag.ExitOnError)
+	configPath := configPathFlag(flags)
+	flags.Parse(args)
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	client := redis.NewClient(&redis.Options{
+		DB:       conf.RedisClientOptions.DB,
+		Password: conf.RedisClientOptions.Password,
+		Addr:     conf.RedisClientOptions.Addr,
+	})
+	defer client.Close()
+	deleted, err := media.DeleteLegacyCacheKeys(client)
+	fmt.Printf("%d legacy keys deleted\n", deleted)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// watchConfig reloads the config of the service on SIGHUP
+// and when the config file changes, until stop is called.
+func watchConfig(configPath string, service *handler.Service) (stop func()) {
+	reload := func() {
+		conf, err := config.Load(configPath)
+		if err != nil {
+			log.Println("config was not reloaded:", err)
+			return
+		}
+		if err := service.Reload(conf); err != nil {
+			log.Println("config was not reloaded:", err)
+		}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			reload()
+		}
+	}()
+	stopWatch := func() error { return nil }
+	if configPath != "" {
+		var err error
+		if stopWatch, err = config.Watch(configPath, reload); err != nil {
+			log.Println("config file is not watched:", err)
+			stopWatch = func() error { return nil }
+		}
+	}
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+		stopWatch()
+	}
+}
+
+func newServer(conf *config.ServiceConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", conf.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(conf.Server.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(conf.Server.ReadTimeout),
+		WriteTimeout:      time.Duration(conf.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(conf.Server.IdleTimeout),
+	}
+}
+
 func main() {
-	router := mux.NewRouter()
-	router.HandleFunc("/api/diff/{currency_id}", handler.DiffHandler).Methods("GET")
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Config.Port), router))
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(checkConfig(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cleanup-cache" {
+		os.Exit(cleanupCache(os.Args[2:]))
+	}
+	configPath := configPathFlag(flag.CommandLine)
+	flag.Parse()
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	service, err := handler.NewService(conf, handler.Dependencies{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	stopWatchingConfig := watchConfig(*configPath, service)
+	syncCtx, stopSyncingCurrencies := context.WithCancel(context.Background())
+	go service.SyncCurrencies(syncCtx)
+
+	server := newServer(conf, service.Router())
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		stopWatchingConfig()
+		stopSyncingCurrencies()
+		service.Close()
+		log.Fatal(err)
+	case sig := <-shutdown:
+		log.Printf("got %s, shutting down", sig)
+	}
+
+	// stop accepting new connections and wait for the requests in progress
+	stopWatchingConfig()
+	stopSyncingCurrencies()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Server.ShutdownTimeout))
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("requests in progress were not finished:", err)
+	}
+	if err := service.Close(); err != nil {
+		log.Println("redis client was not closed:", err)
+	}
+	log.Println("service stopped")
 }
@@ -0,0 +1,85 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Ghytro/ab_interview/common"
+)
+
+var ErrIncorrectGiphyToken = errors.New("incorrect token provided to giphy api")
+var errUnexpectedGiphyStatus = errors.New("unexpected giphy response status")
+
+// GiphyProvider searches gifs with giphy-compatible api.
+type GiphyProvider struct {
+	BaseUrl          string
+	MediaBaseUrl     string
+	ApiToken         string
+	SearchQueryLimit int
+	Client           *http.Client
+}
+
+func (*GiphyProvider) Name() string {
+	return "giphy"
+}
+
+func (p *GiphyProvider) SearchGifIds(ctx context.Context, searchQuery string) ([]string, error) {
+	resp, err := common.HttpGet(
+		ctx,
+		p.Client,
+		fmt.Sprintf(
+			"%sgifs/search?q=%s&api_key=%s&limit=%d",
+			p.BaseUrl,
+			url.QueryEscape(searchQuery),
+			p.ApiToken,
+			p.SearchQueryLimit,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ErrIncorrectGiphyToken
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("%w: %s", errUnexpectedGiphyStatus, resp.Status)
+	}
+	unmarshaled := new(
+		struct {
+			Data []struct {
+				Id string `json:"id"`
+			} `json:"data"`
+		},
+	)
+	if err := json.NewDecoder(resp.Body).Decode(unmarshaled); err != nil {
+		return nil, err
+	}
+	result := make([]string, len(unmarshaled.Data))
+	for i, d := range unmarshaled.Data {
+		result[i] = d.Id
+	}
+	return result, nil
+}
+
+func (p *GiphyProvider) GifUrl(gifId string) string {
+	return fmt.Sprintf("%s%s/giphy.gif", p.MediaBaseUrl, gifId)
+}
+
+func (p *GiphyProvider) GifContent(ctx context.Context, gifId string) ([]byte, error) {
+	resp, err := common.HttpGet(ctx, p.Client, p.GifUrl(gifId))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// error pages must not be cached as gifs
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedGiphyStatus, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
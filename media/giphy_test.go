@@ -0,0 +1,64 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiphyProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/media/first/giphy.gif":
+			w.Write([]byte("GIF89a-first"))
+		case r.URL.Query().Get("api_key") != "token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/gifs/search" && r.URL.Query().Get("q") == "hello world":
+			w.Write([]byte(`{"data": [{"id": "first"}, {"id": "second"}]}`))
+		case r.URL.Path == "/gifs/search" && r.URL.Query().Get("q") == "limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`<html>too many requests</html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	provider := &GiphyProvider{
+		BaseUrl:          server.URL + "/",
+		MediaBaseUrl:     server.URL + "/media/",
+		ApiToken:         "token",
+		SearchQueryLimit: 10,
+		Client:           server.Client(),
+	}
+
+	gifIds, err := provider.SearchGifIds(context.Background(), "hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gifIds) != 2 || gifIds[0] != "first" || gifIds[1] != "second" {
+		t.Fatalf("unexpected gif ids found: %v", gifIds)
+	}
+	if url := provider.GifUrl("first"); url != server.URL+"/media/first/giphy.gif" {
+		t.Fatalf("unexpected gif url: %s", url)
+	}
+	content, err := provider.GifContent(context.Background(), "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "GIF89a-first" {
+		t.Fatalf("unexpected gif content: %q", content)
+	}
+	if _, err := provider.GifContent(context.Background(), "missing"); !errors.Is(err, errUnexpectedGiphyStatus) {
+		t.Fatalf("expected %v for missing gif, got %v", errUnexpectedGiphyStatus, err)
+	}
+	if _, err := provider.SearchGifIds(context.Background(), "limited"); !errors.Is(err, errUnexpectedGiphyStatus) {
+		t.Fatalf("expected %v for exceeded limit, got %v", errUnexpectedGiphyStatus, err)
+	}
+
+	provider.ApiToken = "incorrect"
+	if _, err := provider.SearchGifIds(context.Background(), "hello world"); err != ErrIncorrectGiphyToken {
+		t.Fatalf("expected error %v, but got %v", ErrIncorrectGiphyToken, err)
+	}
+}
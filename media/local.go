@@ -0,0 +1,53 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var errIncorrectLocalGifId = errors.New("incorrect local gif id")
+
+// LocalProvider takes gifs from the directories inside Dir named
+// after the search queries, e.g. Dir/rich/*.gif. Ids of the gifs
+// are their paths relative to Dir.
+type LocalProvider struct {
+	Dir string
+}
+
+func (*LocalProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalProvider) SearchGifIds(_ context.Context, searchQuery string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(p.Dir, searchQuery))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoGifsFound
+		}
+		return nil, err
+	}
+	var result []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".gif") {
+			continue
+		}
+		result = append(result, path.Join(searchQuery, e.Name()))
+	}
+	return result, nil
+}
+
+// GifUrl returns empty string since local gifs are not served publicly.
+func (*LocalProvider) GifUrl(gifId string) string {
+	return ""
+}
+
+func (p *LocalProvider) GifContent(_ context.Context, gifId string) ([]byte, error) {
+	if gifId != path.Clean(gifId) || strings.HasPrefix(gifId, "..") || path.IsAbs(gifId) {
+		return nil, errIncorrectLocalGifId
+	}
+	return os.ReadFile(filepath.Join(p.Dir, filepath.FromSlash(gifId)))
+}
@@ -0,0 +1,35 @@
+package media
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestLocalProvider(t *testing.T) {
+	provider := &LocalProvider{Dir: "testdata/gifs"}
+	gifIds, err := provider.SearchGifIds(context.Background(), "rich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(gifIds)
+	if len(gifIds) != 2 || gifIds[0] != "rich/first.gif" || gifIds[1] != "rich/second.GIF" {
+		t.Fatalf("unexpected gif ids found: %v", gifIds)
+	}
+	content, err := provider.GifContent(context.Background(), gifIds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "GIF89a-rich-1" {
+		t.Fatalf("unexpected content of gif %s: %q", gifIds[0], content)
+	}
+
+	if _, err := provider.SearchGifIds(context.Background(), "meh"); err != ErrNoGifsFound {
+		t.Fatalf("expected error %v for missing directory, but got %v", ErrNoGifsFound, err)
+	}
+	for _, gifId := range [...]string{"../gifs/rich/first.gif", "/etc/passwd", "rich/../../local.go"} {
+		if _, err := provider.GifContent(context.Background(), gifId); err != errIncorrectLocalGifId {
+			t.Fatalf("expected error %v for gif id %s, but got %v", errIncorrectLocalGifId, gifId, err)
+		}
+	}
+}
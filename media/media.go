@@ -0,0 +1,216 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/tenor"
+	"github.com/go-redis/redis"
+)
+
+var ErrNoGifsFound = errors.New("no gifs found by the search query")
+var errUnknownGifProvider = errors.New("unknown gif provider")
+
+// GifProvider is an upstream source of gifs.
+type GifProvider interface {
+	Name() string
+	SearchGifIds(ctx context.Context, searchQuery string) ([]string, error)
+	// GifUrl returns the public url of the gif,
+	// or empty string if the gif has no such url.
+	GifUrl(gifId string) string
+	GifContent(ctx context.Context, gifId string) ([]byte, error)
+}
+
+type Gif struct {
+	Id            string
+	Url           string
+	BinaryContent []byte
+}
+
+// CacheTTL is how long the search results and the gifs
+// are cached, zero means until evicted.
+type CacheTTL struct {
+	GifIds time.Duration
+	Gifs   time.Duration
+}
+
+// Store gets the gifs from the provider and caches them.
+type Store struct {
+	// search results
+	cache  cache.Cache
+	flight *cache.Flight
+	// the gifs themselves, may be bounded by their total size
+	gifCache cache.Cache
+	logger   *common.Logger
+	ttl      CacheTTL
+	provider GifProvider
+}
+
+// NewStore creates the store of the gifs from the provider. Concurrent
+// requests of the same search query or gif are coalesced, across the
+// instances too if lock is not nil.
+func NewStore(
+	c, gifCache cache.Cache,
+	lock *cache.Lock,
+	logger *common.Logger,
+	ttl CacheTTL,
+	provider GifProvider,
+) *Store {
+	return &Store{c, cache.NewFlight(lock), gifCache, logger, ttl, provider}
+}
+
+// NewProviderFromConfig creates the provider selected in config.
+func NewProviderFromConfig(conf *config.ServiceConfig, client *http.Client) (GifProvider, error) {
+	switch conf.GifProvider {
+	case "tenor":
+		return &tenor.Provider{
+			BaseUrl:             conf.TenorBaseUrl,
+			MediaStorageBaseUrl: conf.TenorMediaStorageBaseUrl,
+			ApiToken:            conf.TenorApiToken,
+			SearchQueryLimit:    conf.TenorSearchQueryLimit,
+			Client:              client,
+		}, nil
+	case "giphy":
+		return &GiphyProvider{
+			BaseUrl:          conf.Giphy.BaseUrl,
+			MediaBaseUrl:     conf.Giphy.MediaBaseUrl,
+			ApiToken:         conf.Giphy.ApiToken,
+			SearchQueryLimit: conf.Giphy.SearchQueryLimit,
+			Client:           client,
+		}, nil
+	case "local":
+		return &LocalProvider{Dir: conf.LocalGifs.Dir}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", errUnknownGifProvider, conf.GifProvider)
+}
+
+// keys of the gifs cached before the providers became pluggable, the gifs
+// were kept without ttl, so the keys stay in redis until deleted
+const legacyCacheKeysPattern = "tenor_cache:*"
+
+// DeleteLegacyCacheKeys deletes the gifs and search results cached in redis
+// under the keys used before the providers became pluggable, nothing reads
+// them anymore. Returns the number of deleted keys.
+func DeleteLegacyCacheKeys(client *redis.Client) (int, error) {
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(cursor, legacyCacheKeysPattern, 1000).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := client.Del(keys...).Result()
+			deleted += int(n)
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+func gifIdsCacheKey(providerName, searchQuery string) string {
+	return fmt.Sprintf("gif_cache:%s:gif_ids:%s", providerName, searchQuery)
+}
+
+func gifCacheKey(providerName, gifId string) string {
+	return fmt.Sprintf("gif_cache:%s:gif:%s", providerName, gifId)
+}
+
+func (s *Store) searchGifIds(ctx context.Context, searchQuery string) ([]string, error) {
+	gifIds, err := s.provider.SearchGifIds(ctx, searchQuery)
+	if err != nil {
+		return nil, err
+	}
+	if len(gifIds) == 0 {
+		return nil, ErrNoGifsFound
+	}
+	return gifIds, nil
+}
+
+// getRandomGifId picks the gif id from the search results. The results
+// are shared by the concurrent requests, so a random gif is still picked
+// for each of them.
+func (s *Store) getRandomGifId(ctx context.Context, searchQuery string) (string, error) {
+	key := gifIdsCacheKey(s.provider.Name(), searchQuery)
+	gifIds, err := s.flight.ReadThrough(
+		ctx,
+		s.logger,
+		"media.getRandomGifId",
+		key,
+		func() (interface{}, error) {
+			gifId, err := s.cache.SRandMember(key)
+			if err != nil {
+				return nil, err
+			}
+			return []string{gifId}, nil
+		},
+		func() (interface{}, error) { return s.searchGifIds(ctx, searchQuery) },
+		func(gifIds interface{}) error { return s.cache.SAdd(key, s.ttl.GifIds, gifIds.([]string)...) },
+	)
+	if err != nil {
+		return "", err
+	}
+	ids := gifIds.([]string)
+	return ids[rand.Intn(len(ids))], nil
+}
+
+func (s *Store) getGifByIdFromProvider(ctx context.Context, gifId string) (*Gif, error) {
+	content, err := s.provider.GifContent(ctx, gifId)
+	if err != nil {
+		return nil, err
+	}
+	return &Gif{gifId, s.provider.GifUrl(gifId), content}, nil
+}
+
+func (s *Store) getGifById(ctx context.Context, gifId string) (*Gif, error) {
+	key := gifCacheKey(s.provider.Name(), gifId)
+	gif, err := s.flight.ReadThrough(
+		ctx,
+		s.logger,
+		"media.getGifById",
+		key,
+		func() (interface{}, error) {
+			content, err := s.gifCache.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			return &Gif{gifId, s.provider.GifUrl(gifId), content}, nil
+		},
+		func() (interface{}, error) { return s.getGifByIdFromProvider(ctx, gifId) },
+		func(gif interface{}) error { return s.gifCache.Set(key, gif.(*Gif).BinaryContent, s.ttl.Gifs) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return gif.(*Gif), nil
+}
+
+func (s *Store) GetRandomGif(ctx context.Context, searchQuery string) (*Gif, error) {
+	gifId, err := s.getRandomGifId(ctx, searchQuery)
+	if err != nil {
+		return nil, err
+	}
+	return s.getGifById(ctx, gifId)
+}
+
+// GetRandomGifInfo works like GetRandomGif, but doesn't download
+// the gif itself, only its id and url are filled.
+func (s *Store) GetRandomGifInfo(ctx context.Context, searchQuery string) (*Gif, error) {
+	gifId, err := s.getRandomGifId(ctx, searchQuery)
+	if err != nil {
+		return nil, err
+	}
+	return &Gif{Id: gifId, Url: s.provider.GifUrl(gifId)}, nil
+}
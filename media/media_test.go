@@ -0,0 +1,32 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+func TestDeleteLegacyCacheKeys(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	mr.Set("tenor_cache:gif:rich-gif", "GIF89a")
+	mr.SAdd("tenor_cache:gif_ids:rich", "rich-gif")
+	mr.Set(gifCacheKey("tenor", "rich-gif"), "GIF89a")
+
+	deleted, err := DeleteLegacyCacheKeys(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 legacy keys to be deleted, got %d", deleted)
+	}
+	if keys := mr.Keys(); len(keys) != 1 || keys[0] != gifCacheKey("tenor", "rich-gif") {
+		t.Fatalf("expected only the current keys to be kept, got %v", keys)
+	}
+}
@@ -1,103 +1,48 @@
 package openexchange
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
+	"errors"
 	"net/http"
-	"sync"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
-	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/internal/fakes"
 )
 
+var testRates = map[string]map[string]float64{
+	"2003-04-23": {"USD": 1, "EUR": 0.91, "RUB": 31.1},
+	"2010-03-18": {"USD": 1, "EUR": 0.73, "RUB": 29.2},
+	"2020-01-02": {"USD": 1, "EUR": 0.89, "RUB": 61.9},
+}
+
+func newFakeProvider(t *testing.T, apiToken string) *Provider {
+	fake := fakes.NewOpenExchange("token", testRates)
+	t.Cleanup(fake.Close)
+	return &Provider{fake.BaseUrl(), apiToken, fake.Client()}
+}
+
 func TestHistoricalRates(t *testing.T) {
-	dates := [...]string{
-		"2003-04-23",
-		"2010-03-18",
-		"2015-07-24",
-		"2007-09-15",
-		"2020-01-02",
-	}
-	timestamps := [len(dates)]time.Time{}
-	for i, d := range dates {
-		var err error
-		timestamps[i], err = time.Parse("2006-01-02", d)
+	provider := newFakeProvider(t, "token")
+	for date, correctRates := range testRates {
+		timestamp, err := time.Parse("2006-01-02", date)
 		if err != nil {
 			t.Fatal(err)
 		}
-	}
-	errs := make(chan error, len(dates)*2)
-	correctRates, testedRates := [len(dates)]map[string]float64{}, [len(dates)]map[string]float64{}
-	var wg sync.WaitGroup
-	wg.Add(len(dates) * 2)
-	for i, d := range dates {
-		go func(idx int, date string) {
-			defer wg.Done()
-			resp, err := http.Get(
-				fmt.Sprintf(
-					"%shistorical/%s.json?app_id=%s&base=%s",
-					config.Config.OpenExchangeBaseUrl,
-					date,
-					config.Config.OpenExchangeApiToken,
-					config.Config.BaseCurrencyId,
-				),
-			)
-			if err != nil {
-				errs <- err
-				return
-			}
-			switch resp.StatusCode {
-			case http.StatusForbidden:
-				errs <- ErrIncorrectBaseCurrency
-				return
-			case http.StatusBadRequest:
-				errs <- ErrIncorrectDate
-				return
-			case http.StatusUnauthorized:
-				errs <- ErrIncorrectOpenExchangeToken
-				return
-			}
-			respBody, err := io.ReadAll(resp.Body)
-			if err != nil {
-				errs <- err
-				return
-			}
-			resp.Body.Close()
-
-			unmarshaled := new(
-				struct {
-					Rates map[string]interface{} `json:"rates"`
-				},
-			)
-			if err := json.Unmarshal(respBody, unmarshaled); err != nil {
-				errs <- err
-				return
-			}
-			result := make(map[string]float64)
-			for currency, rate := range unmarshaled.Rates {
-				result[currency] = rate.(float64)
-			}
-			correctRates[idx] = result
-		}(i, d)
-		go func(idx int, t time.Time) {
-			defer wg.Done()
-			r, err := HistoricalRates(t)
-			if err != nil {
-				errs <- err
-				return
-			}
-			testedRates[idx] = r
-		}(i, timestamps[i])
-	}
-	wg.Wait()
-	for i, trs := range testedRates {
-		for base, tr := range trs {
-			if cr, ok := correctRates[i][base]; !ok || cr != tr {
+		testedRates, err := provider.HistoricalRates(context.Background(), timestamp, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(testedRates) != len(correctRates) {
+			t.Fatalf("expected %d rates for %s, got %d", len(correctRates), date, len(testedRates))
+		}
+		for currency, tr := range testedRates {
+			if cr, ok := correctRates[currency]; !ok || cr != tr {
 				t.Fatalf(
 					"incorrect rate got for currency %s: expected %f, but got %f",
-					base,
+					currency,
 					cr,
 					tr,
 				)
@@ -105,3 +50,80 @@ func TestHistoricalRates(t *testing.T) {
 		}
 	}
 }
+
+func TestHistoricalRatesErrors(t *testing.T) {
+	timestamp := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		apiToken  string
+		timestamp time.Time
+		base      string
+		err       error
+	}{
+		{"incorrect token", "wrong-token", timestamp, "USD", ErrIncorrectOpenExchangeToken},
+		{"incorrect base", "token", timestamp, "EUR", ErrIncorrectBaseCurrency},
+		{"unknown date", "token", timestamp.AddDate(0, 0, 1), "USD", ErrIncorrectDate},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newFakeProvider(t, tc.apiToken)
+			if _, err := provider.HistoricalRates(context.Background(), tc.timestamp, tc.base); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestHistoricalRatesResponses(t *testing.T) {
+	timestamp := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+		err    error
+	}{
+		{"quota exceeded", http.StatusTooManyRequests, `{"error": true}`, ErrOpenExchangeQuotaExceeded},
+		{"server error", http.StatusBadGateway, "<html>bad gateway</html>", errUnexpectedStatus},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+			provider := &Provider{server.URL + "/", "token", server.Client()}
+			if _, err := provider.HistoricalRates(context.Background(), timestamp, "USD"); !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	// malformed rates are an error rather than a panic
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"rates": {"USD": 1, "EUR": "0.9"}}`))
+	}))
+	defer server.Close()
+	provider := &Provider{server.URL + "/", "token", server.Client()}
+	if _, err := provider.HistoricalRates(context.Background(), timestamp, "USD"); err == nil {
+		t.Fatal("expected error for non-numeric rate")
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	fake := fakes.NewOpenExchange("token", testRates)
+	defer fake.Close()
+	provider := &Provider{fake.BaseUrl(), "", fake.Client()}
+	if _, err := provider.Currencies(context.Background()); !errors.Is(err, errUnexpectedStatus) {
+		t.Fatalf("expected %v, got %v", errUnexpectedStatus, err)
+	}
+
+	fake.Currencies = map[string]string{"EUR": "Euro", "USD": "United States Dollar"}
+	currencies, err := provider.Currencies(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(currencies, fake.Currencies) {
+		t.Fatalf("expected %v, got %v", fake.Currencies, currencies)
+	}
+}
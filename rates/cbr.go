@@ -0,0 +1,103 @@
+package rates
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+)
+
+var errUnexpectedCbrStatus = errors.New("unexpected cbr response status")
+
+// CbrProvider gets official rates of the Central Bank of Russia.
+type CbrProvider struct {
+	BaseUrl string
+	Client  *http.Client
+}
+
+type cbrValCurs struct {
+	Valutes []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+}
+
+// asciiReader replaces all non-ascii bytes with question marks.
+// The feed is in windows-1251, but only ascii fields are used,
+// so there is no need to decode cyrillic currencies names.
+type asciiReader struct {
+	r io.Reader
+}
+
+func (ar *asciiReader) Read(p []byte) (int, error) {
+	n, err := ar.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] >= 0x80 {
+			p[i] = '?'
+		}
+	}
+	return n, err
+}
+
+// parseCbrNumber parses numbers with comma as a decimal separator.
+func parseCbrNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+}
+
+func (*CbrProvider) Name() string {
+	return "cbr"
+}
+
+func (p *CbrProvider) HistoricalRates(ctx context.Context, timestamp time.Time, base string) (map[string]float64, error) {
+	resp, err := common.HttpGet(
+		ctx,
+		p.Client,
+		fmt.Sprintf(
+			"%sXML_daily.asp?date_req=%s",
+			p.BaseUrl,
+			timestamp.Format("02/01/2006"),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// maintenance pages must not look like the absence of rates
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedCbrStatus, resp.Status)
+	}
+	decoder := xml.NewDecoder(resp.Body)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return &asciiReader{input}, nil
+	}
+	valCurs := new(cbrValCurs)
+	if err := decoder.Decode(valCurs); err != nil {
+		return nil, err
+	}
+	if len(valCurs.Valutes) == 0 {
+		return nil, ErrNoRatesForDate
+	}
+
+	// the feed contains the price of Nominal units of currency in rubles
+	result := map[string]float64{"RUB": 1}
+	for _, v := range valCurs.Valutes {
+		nominal, err := parseCbrNumber(v.Nominal)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseCbrNumber(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		result[v.CharCode] = nominal / value
+	}
+	return CrossRates(result, base)
+}
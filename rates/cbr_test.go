@@ -0,0 +1,62 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// names of the currencies are in windows-1251 in the real feed
+const cbrDailyXml = "<?xml version=\"1.0\" encoding=\"windows-1251\"?>" +
+	"<ValCurs Date=\"09.01.2024\" name=\"Foreign Currency Market\">" +
+	"<Valute ID=\"R01235\"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>" +
+	"<Name>\xc4\xee\xeb\xeb\xe0\xf0 \xd1\xd8\xc0</Name><Value>90,4</Value></Valute>" +
+	"<Valute ID=\"R01375\"><NumCode>156</NumCode><CharCode>CNY</CharCode><Nominal>10</Nominal>" +
+	"<Name>\xde\xe0\xed\xfc</Name><Value>125</Value></Valute>" +
+	"</ValCurs>"
+
+func TestCbrProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/XML_daily.asp" || r.URL.Query().Get("date_req") != "09/01/2024" {
+			w.Write([]byte("<?xml version=\"1.0\" encoding=\"windows-1251\"?><ValCurs/>"))
+			return
+		}
+		w.Write([]byte(cbrDailyXml))
+	}))
+	defer server.Close()
+	provider := &CbrProvider{BaseUrl: server.URL + "/", Client: server.Client()}
+
+	timestamp := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	rates, err := provider.HistoricalRates(context.Background(), timestamp, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{"USD": 1, "RUB": 90.4, "CNY": 90.4 / 12.5}
+	for currency, rate := range expected {
+		if !almostEqual(rates[currency], rate) {
+			t.Fatalf("incorrect rate got for currency %s: expected %f, but got %f", currency, rate, rates[currency])
+		}
+	}
+
+	if _, err := provider.HistoricalRates(context.Background(), timestamp.AddDate(0, 0, 1), "USD"); err != ErrNoRatesForDate {
+		t.Fatalf("expected error %v for date without rates, but got %v", ErrNoRatesForDate, err)
+	}
+}
+
+func TestCbrProviderUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`<?xml version="1.0"?><maintenance/>`))
+	}))
+	defer server.Close()
+	provider := &CbrProvider{BaseUrl: server.URL + "/", Client: server.Client()}
+
+	timestamp := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	_, err := provider.HistoricalRates(context.Background(), timestamp, "RUB")
+	if !errors.Is(err, errUnexpectedCbrStatus) {
+		t.Fatalf("expected error %v, but got %v", errUnexpectedCbrStatus, err)
+	}
+}
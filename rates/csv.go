@@ -0,0 +1,79 @@
+package rates
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var errIncorrectCsvRecord = errors.New("incorrect record in rates csv file, expected date,currency,rate")
+
+// CsvProvider gets rates from a static csv file with the header
+// date,currency,rate where the dates are in YYYY-MM-DD format and
+// the rates are relative to Base. The file is read once on the first use.
+type CsvProvider struct {
+	Path string
+	Base string
+
+	once        sync.Once
+	ratesByDate map[string]map[string]float64
+	err         error
+}
+
+func (*CsvProvider) Name() string {
+	return "csv"
+}
+
+func (p *CsvProvider) load() {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		p.err = err
+		return
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	// skipping the header
+	if _, err := reader.Read(); err != nil {
+		p.err = err
+		return
+	}
+	p.ratesByDate = make(map[string]map[string]float64)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			p.err = err
+			return
+		}
+		date, currency := record[0], record[1]
+		rate, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			p.err = errIncorrectCsvRecord
+			return
+		}
+		if _, ok := p.ratesByDate[date]; !ok {
+			p.ratesByDate[date] = map[string]float64{p.Base: 1}
+		}
+		p.ratesByDate[date][currency] = rate
+	}
+}
+
+func (p *CsvProvider) HistoricalRates(_ context.Context, timestamp time.Time, base string) (map[string]float64, error) {
+	p.once.Do(p.load)
+	if p.err != nil {
+		return nil, p.err
+	}
+	rates, ok := p.ratesByDate[timestamp.Format("2006-01-02")]
+	if !ok {
+		return nil, ErrNoRatesForDate
+	}
+	return CrossRates(rates, base)
+}
@@ -0,0 +1,47 @@
+package rates
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCsvProvider(t *testing.T) {
+	provider := &CsvProvider{Path: "testdata/rates.csv", Base: "USD"}
+	testCases := [...]struct {
+		date        string
+		base        string
+		expected    map[string]float64
+		expectedErr error
+	}{
+		{"2024-01-01", "USD", map[string]float64{"USD": 1, "EUR": 0.9, "GBP": 0.8}, nil},
+		{"2024-01-02", "USD", map[string]float64{"USD": 1, "EUR": 0.92}, nil},
+		{"2024-01-01", "GBP", map[string]float64{"USD": 1.25, "EUR": 1.125, "GBP": 1}, nil},
+		{"2024-01-03", "USD", nil, ErrNoRatesForDate},
+		{"2024-01-02", "GBP", nil, ErrIncorrectBaseCurrency},
+	}
+	for _, tc := range testCases {
+		timestamp, err := time.Parse("2006-01-02", tc.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rates, err := provider.HistoricalRates(context.Background(), timestamp, tc.base)
+		if err != tc.expectedErr {
+			t.Fatalf("date %s: expected error %v, but got %v", tc.date, tc.expectedErr, err)
+		}
+		if len(rates) != len(tc.expected) {
+			t.Fatalf("date %s: expected %d rates, but got %d", tc.date, len(tc.expected), len(rates))
+		}
+		for currency, rate := range tc.expected {
+			if !almostEqual(rates[currency], rate) {
+				t.Fatalf(
+					"date %s: incorrect rate got for currency %s: expected %f, but got %f",
+					tc.date,
+					currency,
+					rate,
+					rates[currency],
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+)
+
+var ErrNoCurrenciesProviders = errors.New("none of the rates providers lists currencies")
+
+const currenciesCacheKey = "currencies_cache"
+
+// CurrenciesLister is implemented by the rates providers
+// able to list the currencies they support.
+type CurrenciesLister interface {
+	// Currencies returns the codes and names of the supported currencies.
+	Currencies(ctx context.Context) (map[string]string, error)
+}
+
+// currenciesFromProviders asks the providers able to list currencies
+// in the order from config until one of them responds.
+func (s *Store) currenciesFromProviders(ctx context.Context) (map[string]string, error) {
+	err := ErrNoCurrenciesProviders
+	for _, p := range s.providers {
+		lister, ok := p.RatesProvider.(CurrenciesLister)
+		if !ok {
+			continue
+		}
+		var currencies map[string]string
+		currencies, err = lister.Currencies(ctx)
+		if err == nil && len(currencies) > 0 {
+			return currencies, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			err = ErrNoCurrenciesProviders
+		}
+		s.logger.LogIfVerbose("rates.Currencies: provider " + p.Name() + " failed: " + err.Error())
+	}
+	return nil, err
+}
+
+// Currencies returns the codes and names of the currencies supported by
+// the rates providers. The listing is cached for cacheTTL, so with shared
+// cache it is requested from the providers once per cacheTTL by all instances.
+func (s *Store) Currencies(ctx context.Context, cacheTTL time.Duration) (map[string]string, error) {
+	var currencies map[string]string
+	err := cache.ReadThrough(
+		s.logger,
+		"rates.Currencies",
+		func() (err error) {
+			currencies, err = s.cache.HGetAll(currenciesCacheKey)
+			return err
+		},
+		func() (err error) {
+			currencies, err = s.currenciesFromProviders(ctx)
+			return err
+		},
+		func() error { return s.cache.HSet(currenciesCacheKey, currencies, cacheTTL) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return currencies, nil
+}
@@ -0,0 +1,62 @@
+package rates
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+type listingProvider struct {
+	fakeProvider
+	currencies map[string]string
+	listings   int
+}
+
+func (p *listingProvider) Currencies(context.Context) (map[string]string, error) {
+	p.listings++
+	return p.currencies, nil
+}
+
+func TestCurrencies(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer redisClient.Close()
+	redisCache := cache.NewRedis(redisClient)
+
+	notListing := &fakeProvider{name: "not listing"}
+	store := NewStore(redisCache, nil, &common.Logger{}, CacheTTL{}, "USD", time.Minute, []RatesProvider{notListing})
+	if _, err := store.Currencies(context.Background(), time.Hour); err != ErrNoCurrenciesProviders {
+		t.Fatalf("expected %v, got %v", ErrNoCurrenciesProviders, err)
+	}
+
+	listing := &listingProvider{
+		fakeProvider: fakeProvider{name: "listing"},
+		currencies:   map[string]string{"EUR": "Euro", "USD": "US Dollar"},
+	}
+	store = NewStore(redisCache, nil, &common.Logger{}, CacheTTL{}, "USD", time.Minute, []RatesProvider{notListing, listing})
+	for i := 0; i < 2; i++ {
+		currencies, err := store.Currencies(context.Background(), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(currencies, listing.currencies) {
+			t.Fatalf("expected %v, got %v", listing.currencies, currencies)
+		}
+	}
+	if listing.listings != 1 {
+		t.Fatalf("expected currencies to be listed once and then taken from cache, but listed %d times", listing.listings)
+	}
+	if ttl := mr.TTL(currenciesCacheKey); ttl != time.Hour {
+		t.Fatalf("expected currencies to be cached for an hour, got %v", ttl)
+	}
+}
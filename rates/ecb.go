@@ -0,0 +1,114 @@
+package rates
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+)
+
+var errUnexpectedEcbStatus = errors.New("unexpected ecb response status")
+
+// the files are updated once a day, so the parsed ones
+// are kept for a while instead of downloading them again
+const ecbFileTTL = time.Hour
+
+// EcbProvider gets reference rates published daily by European Central Bank.
+// The rates are published on working days only, so the rates for a weekend
+// or a holiday are the ones of the last working day before it.
+type EcbProvider struct {
+	BaseUrl string
+	Client  *http.Client
+
+	m sync.Mutex
+	// parsed files by name
+	files map[string]*ecbFile
+}
+
+type ecbFile struct {
+	// serializes the downloads of the file
+	m        sync.Mutex
+	envelope *ecbEnvelope
+	fetched  time.Time
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func (*EcbProvider) Name() string {
+	return "ecb"
+}
+
+// envelope returns the parsed file, downloading it
+// only if it was not downloaded within ecbFileTTL.
+func (p *EcbProvider) envelope(ctx context.Context, fileName string) (*ecbEnvelope, error) {
+	p.m.Lock()
+	if p.files == nil {
+		p.files = make(map[string]*ecbFile)
+	}
+	file, ok := p.files[fileName]
+	if !ok {
+		file = new(ecbFile)
+		p.files[fileName] = file
+	}
+	p.m.Unlock()
+
+	file.m.Lock()
+	defer file.m.Unlock()
+	if file.envelope != nil && time.Since(file.fetched) < ecbFileTTL {
+		return file.envelope, nil
+	}
+	resp, err := common.HttpGet(ctx, p.Client, p.BaseUrl+fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedEcbStatus, resp.Status)
+	}
+	envelope := new(ecbEnvelope)
+	if err := xml.NewDecoder(resp.Body).Decode(envelope); err != nil {
+		return nil, err
+	}
+	file.envelope, file.fetched = envelope, time.Now()
+	return envelope, nil
+}
+
+func (p *EcbProvider) HistoricalRates(ctx context.Context, timestamp time.Time, base string) (map[string]float64, error) {
+	// full history is a large file, so the one
+	// with the last 90 days is used when possible
+	fileName := "eurofxref-hist.xml"
+	if time.Since(timestamp) < 89*24*time.Hour {
+		fileName = "eurofxref-hist-90d.xml"
+	}
+	envelope, err := p.envelope(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	date := timestamp.Format("2006-01-02")
+	for _, day := range envelope.Days {
+		// days are sorted from the latest to the earliest one
+		if day.Time > date {
+			continue
+		}
+		result := map[string]float64{"EUR": 1}
+		for _, r := range day.Rates {
+			result[r.Currency] = r.Rate
+		}
+		return CrossRates(result, base)
+	}
+	return nil, ErrNoRatesForDate
+}
@@ -0,0 +1,93 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const ecbHistXml = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-01-05">
+			<Cube currency="USD" rate="1.0921"/>
+			<Cube currency="GBP" rate="0.86"/>
+		</Cube>
+		<Cube time="2024-01-04">
+			<Cube currency="USD" rate="1.0953"/>
+			<Cube currency="GBP" rate="0.8625"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestEcbProvider(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/eurofxref-hist.xml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(ecbHistXml))
+	}))
+	defer server.Close()
+	provider := &EcbProvider{BaseUrl: server.URL + "/", Client: server.Client()}
+
+	testCases := [...]struct {
+		date        string
+		base        string
+		expected    map[string]float64
+		expectedErr error
+	}{
+		{"2024-01-04", "EUR", map[string]float64{"EUR": 1, "USD": 1.0953, "GBP": 0.8625}, nil},
+		// weekend takes the rates of the last working day
+		{"2024-01-07", "EUR", map[string]float64{"EUR": 1, "USD": 1.0921, "GBP": 0.86}, nil},
+		{"2024-01-05", "GBP", map[string]float64{"EUR": 1 / 0.86, "USD": 1.0921 / 0.86, "GBP": 1}, nil},
+		{"2024-01-03", "EUR", nil, ErrNoRatesForDate},
+		{"2024-01-05", "RUB", nil, ErrIncorrectBaseCurrency},
+	}
+	for _, tc := range testCases {
+		timestamp, err := time.Parse("2006-01-02", tc.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rates, err := provider.HistoricalRates(context.Background(), timestamp, tc.base)
+		if err != tc.expectedErr {
+			t.Fatalf("date %s: expected error %v, but got %v", tc.date, tc.expectedErr, err)
+		}
+		for currency, rate := range tc.expected {
+			if !almostEqual(rates[currency], rate) {
+				t.Fatalf(
+					"date %s: incorrect rate got for currency %s: expected %f, but got %f",
+					tc.date,
+					currency,
+					rate,
+					rates[currency],
+				)
+			}
+		}
+	}
+	// the file is parsed once for all the dates
+	if requests != 1 {
+		t.Fatalf("expected the file to be downloaded once, but it was downloaded %d times", requests)
+	}
+}
+
+func TestEcbProviderUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<html>internal error</html>"))
+	}))
+	defer server.Close()
+	provider := &EcbProvider{BaseUrl: server.URL + "/", Client: server.Client()}
+
+	timestamp := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	_, err := provider.HistoricalRates(context.Background(), timestamp, "EUR")
+	if !errors.Is(err, errUnexpectedEcbStatus) {
+		t.Fatalf("expected error %v, but got %v", errUnexpectedEcbStatus, err)
+	}
+}
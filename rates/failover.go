@@ -0,0 +1,70 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/openexchange"
+)
+
+var errNoRatesProviders = errors.New("no rates providers configured")
+
+type providerWithHealth struct {
+	RatesProvider
+	health *common.HealthChecker
+}
+
+// isProviderFault reports whether the error is caused by the provider
+// itself rather than by the requested data, so that the provider should
+// not be asked for a while.
+func isProviderFault(err error) bool {
+	for _, dataErr := range [...]error{
+		ErrIncorrectBaseCurrency,
+		ErrNoRatesForDate,
+		openexchange.ErrIncorrectBaseCurrency,
+		openexchange.ErrIncorrectDate,
+	} {
+		if errors.Is(err, dataErr) {
+			return false
+		}
+	}
+	return true
+}
+
+// historicalRatesFromProviders asks the providers one by one in the order
+// from config until one of them responds with the rates or ctx is done.
+func (s *Store) historicalRatesFromProviders(ctx context.Context, timestamp time.Time, base string) (*Table, error) {
+	// providers that failed recently are asked last instead of being
+	// skipped, so that all of them failing doesn't fail every request
+	ordered := make([]*providerWithHealth, 0, len(s.providers))
+	var unavailable []*providerWithHealth
+	for _, p := range s.providers {
+		if p.health.IsAvailable() {
+			ordered = append(ordered, p)
+		} else {
+			unavailable = append(unavailable, p)
+		}
+	}
+	ordered = append(ordered, unavailable...)
+
+	err := errNoRatesProviders
+	for _, p := range ordered {
+		var rates map[string]float64
+		rates, err = p.HistoricalRates(ctx, timestamp, base)
+		if err == nil {
+			return &Table{p.Name(), rates}, nil
+		}
+		// the request is cancelled or timed out, that's not the fault
+		// of the provider and there's no point in asking the others
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if isProviderFault(err) {
+			p.health.SetUnavailable()
+		}
+		s.logger.LogIfVerbose("rates.HistoricalRates: provider " + p.Name() + " failed: " + err.Error())
+	}
+	return nil, err
+}
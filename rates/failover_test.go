@@ -0,0 +1,114 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+)
+
+type fakeProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) HistoricalRates(_ context.Context, timestamp time.Time, base string) (map[string]float64, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return map[string]float64{base: 1}, nil
+}
+
+func TestHistoricalRatesFromProviders(t *testing.T) {
+	errConnectionRefused := errors.New("connection refused")
+	failing := &fakeProvider{name: "failing", err: errConnectionRefused}
+	noData := &fakeProvider{name: "no data", err: ErrNoRatesForDate}
+	working := &fakeProvider{name: "working"}
+	store := NewStore(cache.Noop{}, nil, &common.Logger{}, CacheTTL{}, "USD", time.Minute, []RatesProvider{failing, noData, working})
+
+	for i := 0; i < 2; i++ {
+		table, err := store.historicalRatesFromProviders(context.Background(), time.Now(), "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if table.Provider != working.name {
+			t.Fatalf("expected rates from provider %s, but got from %s", working.name, table.Provider)
+		}
+	}
+	if store.providers[0].health.IsAvailable() {
+		t.Fatal("expected failing provider to be unavailable")
+	}
+	if !store.providers[1].health.IsAvailable() {
+		t.Fatal("expected provider without data to stay available")
+	}
+	// failing provider is asked only once, after that it goes
+	// to the end of the queue and the working one responds first
+	if failing.calls != 1 || noData.calls != 2 || working.calls != 2 {
+		t.Fatalf(
+			"unexpected number of calls to providers: %d, %d, %d",
+			failing.calls,
+			noData.calls,
+			working.calls,
+		)
+	}
+
+	working.err = errConnectionRefused
+	if _, err := store.historicalRatesFromProviders(context.Background(), time.Now(), "USD"); err != errConnectionRefused {
+		t.Fatalf("expected error %v when all providers fail, but got %v", errConnectionRefused, err)
+	}
+	if failing.calls != 2 {
+		t.Fatalf("expected unavailable provider to be asked when others fail, but it was not")
+	}
+}
+
+func TestHistoricalRatesFromProvidersCancelled(t *testing.T) {
+	first := &fakeProvider{name: "first", err: context.Canceled}
+	second := &fakeProvider{name: "second"}
+	store := NewStore(cache.Noop{}, nil, &common.Logger{}, CacheTTL{}, "USD", time.Minute, []RatesProvider{first, second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.historicalRatesFromProviders(ctx, time.Now(), "USD"); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if second.calls != 0 {
+		t.Fatal("expected other providers not to be asked after cancellation")
+	}
+	if !store.providers[0].health.IsAvailable() {
+		t.Fatal("expected provider to stay available after cancellation")
+	}
+}
+
+func TestHistoricalRatesFromProvidersCooldown(t *testing.T) {
+	errConnectionRefused := errors.New("connection refused")
+	// wrapped errors about the data are not the fault of the provider
+	noData := &fakeProvider{name: "no data", err: fmt.Errorf("%w: 2024-01-01", ErrNoRatesForDate)}
+	failing := &fakeProvider{name: "failing", err: errConnectionRefused}
+	working := &fakeProvider{name: "working"}
+	cooldown := 50 * time.Millisecond
+	store := NewStore(cache.Noop{}, nil, &common.Logger{}, CacheTTL{}, "USD", cooldown, []RatesProvider{noData, failing, working})
+
+	if _, err := store.historicalRatesFromProviders(context.Background(), time.Now(), "USD"); err != nil {
+		t.Fatal(err)
+	}
+	if !store.providers[0].health.IsAvailable() {
+		t.Fatal("expected provider without data to stay available")
+	}
+	if store.providers[1].health.IsAvailable() {
+		t.Fatal("expected failing provider to be unavailable")
+	}
+	time.Sleep(2 * cooldown)
+	if !store.providers[1].health.IsAvailable() {
+		t.Fatal("expected failing provider to be available after cooldown")
+	}
+}
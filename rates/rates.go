@@ -0,0 +1,219 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/openexchange"
+)
+
+var ErrIncorrectBaseCurrency = errors.New("incorrect base currency")
+var ErrNoRatesForDate = errors.New("no rates data for the given date")
+var errUnknownRatesProvider = errors.New("unknown rates provider")
+
+// RatesProvider is an upstream source of currencies rates.
+type RatesProvider interface {
+	Name() string
+	// HistoricalRates returns rates of all the currencies known to
+	// the provider relative to the base at the given date, that is
+	// how many units of currency one unit of base costs.
+	HistoricalRates(ctx context.Context, timestamp time.Time, base string) (map[string]float64, error)
+}
+
+// Table is a set of rates along with the name of the provider they came from.
+type Table struct {
+	Provider string
+	Rates    map[string]float64
+}
+
+// the name of the provider is stored in cache along with the rates
+const providerCacheField = "_provider"
+
+// CacheTTL is how long the rates are cached, zero means until evicted.
+// Rates of the past days don't change, so they may be kept much longer
+// than the rates of the current day, which are updated during the day.
+type CacheTTL struct {
+	CurrentDay time.Duration
+	PastDays   time.Duration
+}
+
+// Store gets the rates from the providers and caches them.
+type Store struct {
+	cache          cache.Cache
+	flight         *cache.Flight
+	logger         *common.Logger
+	ttl            CacheTTL
+	baseCurrencyId string
+	providers      []*providerWithHealth
+	now            func() time.Time
+}
+
+// NewStore creates a store asking the providers in the given order.
+// Only the rates relative to baseCurrencyId are requested from them.
+// A failed provider is asked last until providerCooldown passes.
+// Concurrent requests of the same rates are coalesced, across the
+// instances too if lock is not nil.
+func NewStore(
+	c cache.Cache,
+	lock *cache.Lock,
+	logger *common.Logger,
+	ttl CacheTTL,
+	baseCurrencyId string,
+	providerCooldown time.Duration,
+	providers []RatesProvider,
+) *Store {
+	s := &Store{
+		cache:          c,
+		flight:         cache.NewFlight(lock),
+		logger:         logger,
+		ttl:            ttl,
+		baseCurrencyId: baseCurrencyId,
+		providers:      make([]*providerWithHealth, len(providers)),
+		now:            time.Now,
+	}
+	for i, p := range providers {
+		s.providers[i] = &providerWithHealth{p, common.NewHealthChecker(providerCooldown)}
+	}
+	return s
+}
+
+func NewProvider(name string, conf *config.ServiceConfig, client *http.Client) (RatesProvider, error) {
+	switch name {
+	case "openexchange":
+		return &openexchange.Provider{
+			BaseUrl:  conf.OpenExchangeBaseUrl,
+			ApiToken: conf.OpenExchangeApiToken,
+			Client:   client,
+		}, nil
+	case "ecb":
+		return &EcbProvider{BaseUrl: conf.Ecb.BaseUrl, Client: client}, nil
+	case "cbr":
+		return &CbrProvider{BaseUrl: conf.Cbr.BaseUrl, Client: client}, nil
+	case "csv":
+		return &CsvProvider{Path: conf.CsvRates.Path, Base: conf.CsvRates.BaseCurrencyId}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", errUnknownRatesProvider, name)
+}
+
+// NewProvidersFromConfig creates the providers listed in config in the same order.
+func NewProvidersFromConfig(conf *config.ServiceConfig, client *http.Client) ([]RatesProvider, error) {
+	result := make([]RatesProvider, len(conf.RatesProviders))
+	for i, name := range conf.RatesProviders {
+		p, err := NewProvider(name, conf, client)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = p
+	}
+	return result, nil
+}
+
+func ratesCacheKey(date, base string) string {
+	return fmt.Sprintf("rates_cache:%s:%s", date, base)
+}
+
+// cacheTTL returns how long the rates at the date are cached. Dates are
+// in UTC, the current day lasts until the midnight in UTC. Rates of the
+// future dates are cached as the current ones, the providers may publish
+// them later.
+func (s *Store) cacheTTL(date string) time.Duration {
+	if date < s.now().UTC().Format("2006-01-02") {
+		return s.ttl.PastDays
+	}
+	return s.ttl.CurrentDay
+}
+
+func (s *Store) getHistoricalRatesFromCache(date, base string) (*Table, error) {
+	cacheData, err := s.cache.HGetAll(ratesCacheKey(date, base))
+	if err != nil {
+		return nil, err
+	}
+	result := &Table{Rates: make(map[string]float64)}
+	for k, v := range cacheData {
+		if k == providerCacheField {
+			result.Provider = v
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		result.Rates[k] = f
+	}
+	return result, nil
+}
+
+func (s *Store) addRateToCache(date, base string, table *Table) error {
+	cacheData := make(map[string]string, len(table.Rates)+1)
+	for k, v := range table.Rates {
+		cacheData[k] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	cacheData[providerCacheField] = table.Provider
+	return s.cache.HSet(ratesCacheKey(date, base), cacheData, s.cacheTTL(date))
+}
+
+// CrossRates recalculates rates given relative to one base currency
+// so that they are relative to the newBase.
+func CrossRates(rates map[string]float64, newBase string) (map[string]float64, error) {
+	newBaseRate, ok := rates[newBase]
+	if !ok || newBaseRate == 0 {
+		return nil, ErrIncorrectBaseCurrency
+	}
+	result := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		result[currency] = rate / newBaseRate
+	}
+	return result, nil
+}
+
+func (s *Store) historicalRatesWithCache(
+	ctx context.Context,
+	date, base string,
+	fetch func() (*Table, error),
+) (*Table, error) {
+	table, err := s.flight.ReadThrough(
+		ctx,
+		s.logger,
+		"rates.HistoricalRates",
+		ratesCacheKey(date, base),
+		func() (interface{}, error) { return s.getHistoricalRatesFromCache(date, base) },
+		func() (interface{}, error) { return fetch() },
+		func(table interface{}) error { return s.addRateToCache(date, base, table.(*Table)) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return table.(*Table), nil
+}
+
+// HistoricalRates returns rates of all the currencies relative to the base
+// at the given date. Only the rates relative to the base from config are
+// requested from the providers, the rates for other bases are cross-calculated
+// from them.
+func (s *Store) HistoricalRates(ctx context.Context, timestamp time.Time, base string) (*Table, error) {
+	date := timestamp.Format("2006-01-02")
+	fetch := func() (*Table, error) {
+		return s.historicalRatesFromProviders(ctx, timestamp, base)
+	}
+	if base != s.baseCurrencyId {
+		fetch = func() (*Table, error) {
+			table, err := s.HistoricalRates(ctx, timestamp, s.baseCurrencyId)
+			if err != nil {
+				return nil, err
+			}
+			rates, err := CrossRates(table.Rates, base)
+			if err != nil {
+				return nil, err
+			}
+			return &Table{table.Provider, rates}, nil
+		}
+	}
+	return s.historicalRatesWithCache(ctx, date, base, fetch)
+}
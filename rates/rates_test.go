@@ -0,0 +1,84 @@
+package rates
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Ghytro/ab_interview/cache"
+	"github.com/Ghytro/ab_interview/common"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCrossRates(t *testing.T) {
+	rates := map[string]float64{
+		"USD": 1,
+		"EUR": 0.8,
+		"GBP": 0.5,
+	}
+	crossed, err := CrossRates(rates, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{
+		"USD": 1.25,
+		"EUR": 1,
+		"GBP": 0.625,
+	}
+	for currency, rate := range expected {
+		if crossed[currency] != rate {
+			t.Fatalf(
+				"incorrect cross rate for currency %s: expected %f, but got %f",
+				currency,
+				rate,
+				crossed[currency],
+			)
+		}
+	}
+	if _, err := CrossRates(rates, "RUB"); err != ErrIncorrectBaseCurrency {
+		t.Fatalf("expected error %v for unknown base, but got %v", ErrIncorrectBaseCurrency, err)
+	}
+}
+
+func TestHistoricalRatesCacheTTL(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer redisClient.Close()
+	ttl := CacheTTL{CurrentDay: 10 * time.Minute, PastDays: 0}
+	store := NewStore(cache.NewRedis(redisClient), nil, &common.Logger{}, ttl, "USD", time.Minute, []RatesProvider{&fakeProvider{name: "fake"}})
+	// still the first of February in UTC
+	store.now = func() time.Time {
+		return time.Date(2024, 2, 2, 2, 59, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	}
+
+	testCases := [...]struct {
+		date        time.Time
+		expectedTTL time.Duration
+	}{
+		{time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), ttl.CurrentDay},
+		{time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), ttl.CurrentDay},
+	}
+	for _, tc := range testCases {
+		if _, err := store.HistoricalRates(context.Background(), tc.date, "USD"); err != nil {
+			t.Fatal(err)
+		}
+		key := ratesCacheKey(tc.date.Format("2006-01-02"), "USD")
+		if !mr.Exists(key) {
+			t.Fatalf("%s: rates were not cached", key)
+		}
+		if actual := mr.TTL(key); actual != tc.expectedTTL {
+			t.Fatalf("%s: expected ttl %v, got %v", key, tc.expectedTTL, actual)
+		}
+	}
+}
@@ -1,79 +1,60 @@
 package tenor
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
 
 	"github.com/Ghytro/ab_interview/common"
-	"github.com/Ghytro/ab_interview/config"
-	"github.com/go-redis/redis"
 )
 
-var errNoGifIdsInCache = errors.New("no gif ids found in cache")
-var errNoGifInCache = errors.New("no gif with the given id in cache")
 var ErrIncorrectTenorToken = errors.New("incorrect token provided to tenor api")
+var errUnexpectedStatus = errors.New("unexpected tenor response status")
 
-var redisClient = redis.NewClient(&redis.Options{
-	DB:          config.Config.RedisClientOptions.DB,
-	Password:    config.Config.RedisClientOptions.Password,
-	Addr:        config.Config.RedisClientOptions.Addr,
-	ReadTimeout: time.Millisecond * 100,
-})
-
-type Gif struct {
-	BinaryContent []byte
-}
-
-func getRandomGifIdFromCache(searchQuery string) (string, error) {
-	redisCacheKey := fmt.Sprintf("tenor_cache:gif_ids:%s", searchQuery)
-	gifId, err := redisClient.SRandMember(redisCacheKey).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return "", errNoGifIdsInCache
-		}
-		return "", err
-	}
-	return gifId, nil
+// Provider searches gifs with tenor v1 api.
+type Provider struct {
+	BaseUrl             string
+	MediaStorageBaseUrl string
+	ApiToken            string
+	SearchQueryLimit    int
+	Client              *http.Client
 }
 
-func addGifIdsToCache(searchQuery string, gifIds ...string) {
-	redisCacheKey := fmt.Sprintf("tenor_cache:gif_ids:%s", searchQuery)
-	pipe := redisClient.Pipeline()
-	for _, id := range gifIds {
-		pipe.SAdd(redisCacheKey, id)
-	}
-	pipe.Expire(redisCacheKey, time.Hour*24)
-	pipe.Exec()
+func (*Provider) Name() string {
+	return "tenor"
 }
 
-func getSearchQueryGifIdsFromApi(searchQuery string) ([]string, error) {
-	resp, err := http.Get(
+func (p *Provider) SearchGifIds(ctx context.Context, searchQuery string) ([]string, error) {
+	resp, err := common.HttpGet(
+		ctx,
+		p.Client,
 		fmt.Sprintf(
 			"%ssearch?q=%s&key=%s&limit=%d",
-			config.Config.TenorBaseUrl,
-			searchQuery,
-			config.Config.TenorApiToken,
-			config.Config.TenorSearchQueryLimit,
+			p.BaseUrl,
+			url.QueryEscape(searchQuery),
+			p.ApiToken,
+			p.SearchQueryLimit,
 		),
 	)
 	if err != nil {
 		return nil, err
 	}
-	switch resp.StatusCode {
-	case http.StatusUnauthorized:
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
 		return nil, ErrIncorrectTenorToken
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
 	}
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	resp.Body.Close()
 	unmarshaled := new(
 		struct {
 			Results []struct {
@@ -88,118 +69,34 @@ func getSearchQueryGifIdsFromApi(searchQuery string) ([]string, error) {
 	if err := json.Unmarshal(respBody, unmarshaled); err != nil {
 		return nil, err
 	}
-	result := make([]string, len(unmarshaled.Results))
-	for i, r := range unmarshaled.Results {
-		gifUrl := r.Media[0].Gif.Url
-		splittedGifUrl := strings.Split(gifUrl, "/")
-		gifId := splittedGifUrl[len(splittedGifUrl)-2]
-		result[i] = gifId
-	}
-	return result, nil
-}
-
-func getRandomGifId(searchQuery string) (string, error) {
-	if !common.IsRedisAvailable() {
-		gifIds, err := getSearchQueryGifIdsFromApi(searchQuery)
-		common.LogIfVerbose("tenor.getRandomGifId: redis not available, falling back to api")
-		return gifIds[rand.Intn(len(gifIds))], err
-	}
-	gifId, err := getRandomGifIdFromCache(searchQuery)
-	if err != nil {
-		switch {
-		case common.IsBadRedisConnectionErr(err):
-			common.SetRedisUnavailable()
-			gifIds, err := getSearchQueryGifIdsFromApi(searchQuery)
-			common.LogIfVerbose("tenor.getRandomGifId: bad connection with redis, setting unavailable")
-			return gifIds[rand.Intn(len(gifIds))], err
-		case err == errNoGifIdsInCache:
-			gifIds, err := getSearchQueryGifIdsFromApi(searchQuery)
-			if err != nil {
-				return "", err
-			}
-			addGifIdsToCache(searchQuery, gifIds...)
-			common.LogIfVerbose("tenor.getRandomGifId: no gif ids in cache for the query, adding")
-			return gifIds[rand.Intn(len(gifIds))], nil
-		default:
-			return "", err
+	result := make([]string, 0, len(unmarshaled.Results))
+	for _, r := range unmarshaled.Results {
+		// results without gif or with malformed url are skipped
+		if len(r.Media) == 0 {
+			continue
 		}
-	}
-	common.LogIfVerbose("tenor.getRandomGifId: returning gif id from cache")
-	return gifId, nil
-}
-
-func getGifByIdFromCache(gifId string) (*Gif, error) {
-	redisCacheKey := fmt.Sprintf("tenor_cache:gif:%s", gifId)
-	gifBytes, err := redisClient.Get(redisCacheKey).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, errNoGifInCache
+		splittedGifUrl := strings.Split(r.Media[0].Gif.Url, "/")
+		if len(splittedGifUrl) < 2 || splittedGifUrl[len(splittedGifUrl)-2] == "" {
+			continue
 		}
-		return nil, err
+		result = append(result, splittedGifUrl[len(splittedGifUrl)-2])
 	}
-	return &Gif{gifBytes}, nil
+	return result, nil
 }
 
-func addGifToCache(gifId string, gif *Gif) {
-	redisClient.Set(
-		fmt.Sprintf("tenor_cache:gif:%s", gifId),
-		gif.BinaryContent,
-		0,
-	)
+func (p *Provider) GifUrl(gifId string) string {
+	return fmt.Sprintf("%s%s/tenor.gif", p.MediaStorageBaseUrl, gifId)
 }
 
-func getGifByIdFromTenorApi(gifId string) (*Gif, error) {
-	resp, err := http.Get(
-		fmt.Sprintf(
-			"%s%s/tenor.gif",
-			config.Config.TenorMediaStorageBaseUrl,
-			gifId,
-		),
-	)
+func (p *Provider) GifContent(ctx context.Context, gifId string) ([]byte, error) {
+	resp, err := common.HttpGet(ctx, p.Client, p.GifUrl(gifId))
 	if err != nil {
 		return nil, err
 	}
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	resp.Body.Close()
-	return &Gif{respBody}, nil
-}
-
-func getGifById(gifId string) (*Gif, error) {
-	if !common.IsRedisAvailable() {
-		common.LogIfVerbose("tenor.getGifById: redis not available, falling back to api")
-		return getGifByIdFromTenorApi(gifId)
-	}
-	gif, err := getGifByIdFromCache(gifId)
-	if err != nil {
-		switch {
-		case common.IsBadRedisConnectionErr(err):
-			common.SetRedisUnavailable()
-			common.LogIfVerbose("tenor.getGifById: bad connection with redis, setting unavailable")
-			return getGifByIdFromTenorApi(gifId)
-		case err == errNoGifInCache:
-			gif, err = getGifByIdFromTenorApi(gifId)
-			if err != nil {
-				return nil, err
-			}
-			addGifToCache(gifId, gif)
-			common.LogIfVerbose("tenor.getGifById: no gif found in cache with this id, adding")
-			return gif, nil
-		default:
-			return nil, err
-		}
-	}
-	common.LogIfVerbose("tenor.getGifById: returning gif from cache")
-	return gif, nil
-}
-
-func GetRandomGif(searchQuery string) (*Gif, error) {
-	searchQuery = strings.ReplaceAll(searchQuery, " ", "+")
-	gifId, err := getRandomGifId(searchQuery)
-	if err != nil {
-		return nil, err
+	defer resp.Body.Close()
+	// error pages must not be cached as gifs
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
 	}
-	return getGifById(gifId)
+	return io.ReadAll(resp.Body)
 }
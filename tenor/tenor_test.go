@@ -2,173 +2,97 @@ package tenor
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
+	"errors"
 	"net/http"
-	"strings"
-	"sync"
+	"net/http/httptest"
+	"sort"
 	"testing"
 
-	"github.com/Ghytro/ab_interview/config"
+	"github.com/Ghytro/ab_interview/internal/fakes"
 )
 
-func TestGetGifById(t *testing.T) {
-	gifIds := [...]string{
-		"11ad486604ba6802ffe7cda95ce1f528",
-		"1d73fd5b39730fd356b482128eb3746a",
-		"e128f72733a6ac54534a7a47d578cfa0",
-		"d04f2eaeeb55defe2a5fb19e503f0795",
-		"29fc55a95c15652fe18d1422d06d7b22",
+var testGifs = map[string][]string{
+	"duck":        {"11ad486604ba6802ffe7cda95ce1f528", "1d73fd5b39730fd356b482128eb3746a"},
+	"dog":         {"e128f72733a6ac54534a7a47d578cfa0"},
+	"cat":         {"d04f2eaeeb55defe2a5fb19e503f0795"},
+	"hello world": {"29fc55a95c15652fe18d1422d06d7b22"},
+}
+
+func newFakeProvider(t *testing.T, apiToken string) *Provider {
+	fake := fakes.NewTenor("token", testGifs)
+	t.Cleanup(fake.Close)
+	return &Provider{
+		BaseUrl:             fake.BaseUrl(),
+		MediaStorageBaseUrl: fake.MediaStorageBaseUrl(),
+		ApiToken:            apiToken,
+		SearchQueryLimit:    50,
+		Client:              fake.Client(),
 	}
-	correctGifs := [len(gifIds)]*Gif{}
-	testedGifs := [len(gifIds)]*Gif{}
-	errs := make(chan error, len(gifIds)*2)
-	var wg sync.WaitGroup
-	wg.Add(len(gifIds) * 2)
-	for i, id := range gifIds {
-		go func(idx int, gifId string) {
-			defer wg.Done()
-			resp, err := http.Get(
-				fmt.Sprintf(
-					"https://media.tenor.com/images/%s/tenor.gif",
-					gifId,
-				),
-			)
-			if err != nil {
-				errs <- err
-				return
-			}
-			gifBinaryContent, err := io.ReadAll(resp.Body)
+}
+
+func TestGifContent(t *testing.T) {
+	provider := newFakeProvider(t, "token")
+	for _, gifIds := range testGifs {
+		for _, gifId := range gifIds {
+			content, err := provider.GifContent(context.Background(), gifId)
 			if err != nil {
-				errs <- err
-				return
+				t.Fatal(err)
 			}
-			correctGifs[idx] = &Gif{gifBinaryContent}
-			resp.Body.Close()
-		}(i, id)
-		go func(idx int, gifId string) {
-			defer wg.Done()
-			var err error
-			testedGifs[idx], err = getGifById(gifId)
-			if err != nil {
-				errs <- err
-				return
+			if !bytes.Equal(content, fakes.GifContent(gifId)) {
+				t.Fatalf("Incorrect gif for id %s", gifId)
 			}
-		}(i, id)
+		}
 	}
-	wg.Wait()
-	if len(errs) > 0 {
-		t.Fatal(<-errs)
+	if _, err := provider.GifContent(context.Background(), "missing"); !errors.Is(err, errUnexpectedStatus) {
+		t.Fatalf("expected %v for missing gif, got %v", errUnexpectedStatus, err)
 	}
+}
 
-	for i := range correctGifs {
-		if !bytes.Equal(correctGifs[i].BinaryContent, testedGifs[i].BinaryContent) {
-			t.Fatalf("Incorrect gif for id %s", gifIds[i])
+func TestSearchGifIds(t *testing.T) {
+	provider := newFakeProvider(t, "token")
+	for _, q := range [...]string{"duck", "dog", "cat", "fish", "hello world"} {
+		gifIds, err := provider.SearchGifIds(context.Background(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := append([]string(nil), testGifs[q]...)
+		sort.Strings(expected)
+		sort.Strings(gifIds)
+		if len(gifIds) != len(expected) {
+			t.Fatalf("search query %s: expected %v, got %v", q, expected, gifIds)
+		}
+		for i := range gifIds {
+			if gifIds[i] != expected[i] {
+				t.Fatalf("search query %s: expected %v, got %v", q, expected, gifIds)
+			}
 		}
 	}
 }
 
-type threadSafeStringSlice struct {
-	S []string
-	M sync.Mutex
-}
-
-func (ss *threadSafeStringSlice) Append(s string) {
-	ss.M.Lock()
-	defer ss.M.Unlock()
-	ss.S = append(ss.S, s)
-}
-
-func (ss *threadSafeStringSlice) At(idx int) string {
-	ss.M.Lock()
-	defer ss.M.Unlock()
-	return ss.S[idx]
+func TestSearchGifIdsIncorrectToken(t *testing.T) {
+	provider := newFakeProvider(t, "wrong-token")
+	if _, err := provider.SearchGifIds(context.Background(), "duck"); err != ErrIncorrectTenorToken {
+		t.Fatalf("expected %v, got %v", ErrIncorrectTenorToken, err)
+	}
 }
 
-func (ss *threadSafeStringSlice) Len() int {
-	ss.M.Lock()
-	defer ss.M.Unlock()
-	return len(ss.S)
-}
+func TestSearchGifIdsMalformedResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [
+			{"media": []},
+			{"media": [{"gif": {"url": "tenor.gif"}}]},
+			{"media": [{"gif": {"url": "https://media.tenor.com/images/abc/tenor.gif"}}]}
+		]}`))
+	}))
+	defer server.Close()
+	provider := &Provider{BaseUrl: server.URL + "/", ApiToken: "token", SearchQueryLimit: 50, Client: server.Client()}
 
-func TestGetRandomGifId(t *testing.T) {
-	searchQueries := [...]string{"duck", "dog", "cat", "fish", "hello+world"}
-	possibleGifs := make(map[string][]string)
-	randomGifIds := make(map[string]*threadSafeStringSlice)
-	errs := make(chan error, len(searchQueries)*10)
-	var wg sync.WaitGroup
-	wg.Add(len(searchQueries) * 2)
-	for _, q := range searchQueries {
-		go func(query string) {
-			defer wg.Done()
-			resp, err := http.Get(
-				fmt.Sprintf(
-					// public key here, @ShamelessLad lox
-					"https://g.tenor.com/v1/search?q=%s&key=LIVDSRZULELA&limit=%d",
-					query,
-					config.Config.TenorSearchQueryLimit,
-				),
-			)
-			if err != nil {
-				errs <- err
-				return
-			}
-			respBody, err := io.ReadAll(resp.Body)
-			if err != nil {
-				errs <- err
-				return
-			}
-			resp.Body.Close()
-			unmarshaled := new(
-				struct {
-					Results []struct {
-						Media []struct {
-							Gif struct {
-								Url string `json:"url"`
-							} `json:"gif"`
-						} `json:"media"`
-					} `json:"results"`
-				},
-			)
-			if err := json.Unmarshal(respBody, unmarshaled); err != nil {
-				errs <- err
-				return
-			}
-			for _, r := range unmarshaled.Results {
-				gifUrl := r.Media[0].Gif.Url
-				splittedGifUrl := strings.Split(gifUrl, "/")
-				gifId := splittedGifUrl[len(splittedGifUrl)-2]
-				possibleGifs[query] = append(possibleGifs[query], gifId)
-			}
-		}(q)
-		go func(query string) {
-			defer wg.Done()
-			gifId, err := getRandomGifId(query)
-			if err != nil {
-				errs <- err
-				return
-			}
-			if _, ok := randomGifIds[query]; !ok {
-				randomGifIds[query] = &threadSafeStringSlice{}
-			}
-			randomGifIds[query].Append(gifId)
-		}(q)
+	gifIds, err := provider.SearchGifIds(context.Background(), "duck")
+	if err != nil {
+		t.Fatal(err)
 	}
-	wg.Wait()
-	for query, randomGifs := range randomGifIds {
-		for i := 0; i < randomGifs.Len(); i++ {
-			randomGif := randomGifs.At(i)
-			found := false
-			for _, pg := range possibleGifs[query] {
-				if pg == randomGif {
-					found = true
-					break
-				}
-			}
-			if !found {
-				t.Fatalf("incorrect gif id returned for search query %s: %s", query, randomGif)
-			}
-		}
+	if len(gifIds) != 1 || gifIds[0] != "abc" {
+		t.Fatalf("expected only the well-formed result, got %v", gifIds)
 	}
 }